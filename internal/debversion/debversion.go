@@ -0,0 +1,168 @@
+// Package debversion parses and compares Debian package versions
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+package debversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a Debian version [epoch:]upstream_version[-debian_revision]
+type Version struct {
+	Epoch    int
+	Upstream string
+	Revision string
+}
+
+// Parse a version string
+func Parse(s string) (Version, error) {
+	var v Version
+	s = strings.TrimSpace(s)
+
+	if epoch, rest, ok := strings.Cut(s, ":"); ok {
+		e, err := strconv.Atoi(epoch)
+		if err != nil || e < 0 {
+			return Version{}, fmt.Errorf("invalid epoch %q", epoch)
+		}
+		v.Epoch = e
+		s = rest
+	}
+	if n := strings.LastIndex(s, "-"); n != -1 {
+		v.Revision = s[n+1:]
+		s = s[0:n]
+		if v.Revision == "" {
+			return Version{}, fmt.Errorf("empty revision")
+		}
+	}
+	if s == "" {
+		return Version{}, fmt.Errorf("empty upstream version")
+	}
+	if s[0] < '0' || s[0] > '9' {
+		return Version{}, fmt.Errorf("upstream version %q should start with a digit", s)
+	}
+	for _, c := range s + v.Revision {
+		if !isAlnum(c) && !strings.ContainsRune(".+-~:", c) {
+			return Version{}, fmt.Errorf("invalid character %q", c)
+		}
+	}
+	v.Upstream = s
+
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := v.Upstream
+	if v.Epoch != 0 {
+		s = strconv.Itoa(v.Epoch) + ":" + s
+	}
+	if v.Revision != "" {
+		s += "-" + v.Revision
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is less, equal or greater than o
+func (v Version) Compare(o Version) int {
+	if v.Epoch != o.Epoch {
+		if v.Epoch < o.Epoch {
+			return -1
+		}
+		return 1
+	}
+	if c := compareString(v.Upstream, o.Upstream); c != 0 {
+		return c
+	}
+	return compareString(v.Revision, o.Revision)
+}
+
+func isDigit(c rune) bool { return c >= '0' && c <= '9' }
+func isAlnum(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// order of a character in the non-digit part, "~" sorts before everything,
+// even the end of the string, letters sorts before non-letters
+func order(c rune) int {
+	switch {
+	case c == 0, isDigit(c):
+		return 0
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// compareString compares alternating non-digit and digit parts like dpkg verrevcmp
+func compareString(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	at := func(r []rune, i int) rune {
+		if i < len(r) {
+			return r[i]
+		}
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(ar) || j < len(br) {
+		for (i < len(ar) && !isDigit(ar[i])) || (j < len(br) && !isDigit(br[j])) {
+			ac, bc := order(at(ar, i)), order(at(br, j))
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(ar) && ar[i] == '0' {
+			i++
+		}
+		for j < len(br) && br[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(ar) && isDigit(ar[i]) && j < len(br) && isDigit(br[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(ar[i]) - int(br[j])
+			}
+			i++
+			j++
+		}
+		if i < len(ar) && isDigit(ar[i]) {
+			return 1
+		}
+		if j < len(br) && isDigit(br[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+
+	return 0
+}
+
+// Compare two version strings, returns -1, 0 or 1
+func Compare(a string, b string) (int, error) {
+	av, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	bv, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return av.Compare(bv), nil
+}
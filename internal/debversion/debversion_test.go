@@ -0,0 +1,93 @@
+package debversion_test
+
+import (
+	"testing"
+
+	"github.com/wader/bump/internal/debversion"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		s           string
+		expected    debversion.Version
+		expectedErr string
+	}{
+		{s: "1.2.3", expected: debversion.Version{Upstream: "1.2.3"}},
+		{s: "1:1.2.3-1", expected: debversion.Version{Epoch: 1, Upstream: "1.2.3", Revision: "1"}},
+		{s: "2:1.2-3-4ubuntu1", expected: debversion.Version{Epoch: 2, Upstream: "1.2-3", Revision: "4ubuntu1"}},
+		{s: "1.0~rc1+dfsg-1~bpo12+1", expected: debversion.Version{Upstream: "1.0~rc1+dfsg", Revision: "1~bpo12+1"}},
+		{s: "a:1.0", expectedErr: `invalid epoch "a"`},
+		{s: "1.0-", expectedErr: `empty revision`},
+		{s: "1:", expectedErr: `empty upstream version`},
+		{s: "a1.0", expectedErr: `upstream version "a1.0" should start with a digit`},
+		{s: "1.0_1", expectedErr: `invalid character '_'`},
+	}
+	for _, tC := range testCases {
+		tC := tC
+		t.Run(tC.s, func(t *testing.T) {
+			actual, err := debversion.Parse(tC.s)
+			if tC.expectedErr != "" {
+				if err == nil || err.Error() != tC.expectedErr {
+					t.Fatalf("expected error %q got %v", tC.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tC.expected {
+				t.Errorf("expected %#v got %#v", tC.expected, actual)
+			}
+			if actual.String() != tC.s {
+				t.Errorf("expected string %q got %q", tC.s, actual.String())
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0-0", 0},
+		{"1.0", "1.00", 0},
+		{"0:1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.2", "1.10", -1},
+		{"1:1.0", "2.0", 1},
+		{"1.0-1", "1.0-2", -1},
+		{"1.0-10", "1.0-9", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0~", "1.0", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0a", "1.0+", -1},
+		{"1.0+dfsg", "1.0", 1},
+		{"1.0.1", "1.0a", 1},
+		{"2.30-1ubuntu1", "2.30-1", 1},
+		{"1.0-1~bpo12+1", "1.0-1", -1},
+	}
+	for _, tC := range testCases {
+		tC := tC
+		t.Run(tC.a+" "+tC.b, func(t *testing.T) {
+			actual, err := debversion.Compare(tC.a, tC.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tC.expected {
+				t.Errorf("expected %d got %d", tC.expected, actual)
+			}
+			reverse, err := debversion.Compare(tC.b, tC.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reverse != -tC.expected {
+				t.Errorf("reverse expected %d got %d", -tC.expected, reverse)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package dockerv2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ~/.docker/config.json
+/*
+{
+	"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+		"ghcr.io": {}
+	},
+	"credsStore": "desktop",
+	"credHelpers": {
+		"123456789012.dkr.ecr.eu-west-1.amazonaws.com": "ecr-login"
+	}
+}
+*/
+
+const dockerHubServerURL = "https://index.docker.io/v1/"
+
+// Credentials for a registry
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// BasicAuthHeader returns value for a basic Authorization header
+func (c Credentials) BasicAuthHeader() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+}
+
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// configPath is $DOCKER_CONFIG/config.json or ~/.docker/config.json
+func configPath() (string, error) {
+	if d := os.Getenv("DOCKER_CONFIG"); d != "" {
+		return filepath.Join(d, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// serverHost normalizes config keys like https://host/v1/ to host
+func serverHost(s string) string {
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	s, _, _ = strings.Cut(s, "/")
+	switch s {
+	case "docker.io", "registry-1.docker.io":
+		return defaultRegistryHost
+	}
+	return s
+}
+
+// credential helper protocol
+// echo host | docker-credential-<name> get
+// {"ServerURL":"host","Username":"user","Secret":"pass"}
+// Missing helper is same as no credentials, ex credsStore "desktop" in a
+// config.json shared with a host without docker desktop.
+func helperCredentials(helper string, serverURL string) (*Credentials, error) {
+	stdout := &bytes.Buffer{}
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = stdout
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, nil
+		}
+		// helpers exit with error and message on stdout if not found
+		if strings.Contains(stdout.String(), "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("docker-credential-%s: %w: %s", helper, err, strings.TrimSpace(stdout.String()))
+	}
+
+	var resp struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("docker-credential-%s: %w", helper, err)
+	}
+	// "<token>" is a identity token that needs oauth2, not supported
+	if resp.Username == "" || resp.Username == "<token>" {
+		return nil, nil
+	}
+
+	return &Credentials{Username: resp.Username, Password: resp.Secret}, nil
+}
+
+// ConfigCredentials looks up credentials for a registry host in docker
+// config.json using credHelpers, auths and credsStore in that order.
+// Returns nil if there are no config or credentials.
+func ConfigCredentials(host string) (*Credentials, error) {
+	p, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var c dockerConfig
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+
+	host = serverHost(host)
+	serverURL := host
+	if host == defaultRegistryHost {
+		serverURL = dockerHubServerURL
+	}
+
+	for k, helper := range c.CredHelpers {
+		if serverHost(k) == host {
+			return helperCredentials(helper, serverURL)
+		}
+	}
+	for k, a := range c.Auths {
+		if serverHost(k) != host || a.Auth == "" {
+			continue
+		}
+		d, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: auths %s: %w", p, k, err)
+		}
+		username, password, ok := strings.Cut(string(d), ":")
+		if !ok {
+			return nil, fmt.Errorf("%s: auths %s: should be base64 of username:password", p, k)
+		}
+		return &Credentials{Username: username, Password: password}, nil
+	}
+	if c.CredsStore != "" {
+		return helperCredentials(c.CredsStore, serverURL)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,250 @@
+package dockerv2_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/dockerv2"
+)
+
+func writeConfig(t *testing.T, config string) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+func TestConfigCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script credential helper")
+	}
+
+	helperDir := t.TempDir()
+	// echo input back as username to test server URL
+	helper := "#!/bin/sh\n" +
+		`read -r url` + "\n" +
+		`case "$url" in *notfound*) echo "credentials not found in native keychain"; exit 1;; esac` + "\n" +
+		`echo "{\"ServerURL\":\"$url\",\"Username\":\"$url\",\"Secret\":\"secret\"}"` + "\n"
+	if err := os.WriteFile(filepath.Join(helperDir, "docker-credential-test"), []byte(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", helperDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	writeConfig(t, `{
+	"auths": {
+		"https://index.docker.io/v1/": {"auth": "aHViOnB3"},
+		"https://auths.example.com": {"auth": "dXNlcjpwYTpzcw=="},
+		"store.example.com": {}
+	},
+	"credsStore": "test",
+	"credHelpers": {
+		"helper.example.com": "test",
+		"notfound.example.com": "test"
+	}
+}`)
+
+	testCases := []struct {
+		host     string
+		expected *dockerv2.Credentials
+	}{
+		{host: "index.docker.io", expected: &dockerv2.Credentials{Username: "hub", Password: "pw"}},
+		{host: "auths.example.com", expected: &dockerv2.Credentials{Username: "user", Password: "pa:ss"}},
+		{host: "helper.example.com", expected: &dockerv2.Credentials{Username: "helper.example.com", Password: "secret"}},
+		{host: "store.example.com", expected: &dockerv2.Credentials{Username: "store.example.com", Password: "secret"}},
+		{host: "notfound.example.com", expected: nil},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.host, func(t *testing.T) {
+			actual, err := dockerv2.ConfigCredentials(tC.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (actual == nil) != (tC.expected == nil) || (actual != nil && *actual != *tC.expected) {
+				t.Fatalf("expected %v got %v", tC.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfigCredentialsNoConfig(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	c, err := dockerv2.ConfigCredentials("index.docker.io")
+	if err != nil || c != nil {
+		t.Fatalf("expected no credentials and no error got %v %v", c, err)
+	}
+}
+
+func TestConfigCredentialsMissingHelper(t *testing.T) {
+	writeConfig(t, `{"credsStore": "bump-missing-helper"}`)
+	c, err := dockerv2.ConfigCredentials("index.docker.io")
+	if err != nil || c != nil {
+		t.Fatalf("expected no credentials and no error got %v %v", c, err)
+	}
+}
+
+func TestCredentialsFallbackAnonymous(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script credential helper")
+	}
+
+	helperDir := t.TempDir()
+	helpers := map[string]string{
+		"notfound": `echo "credentials not found in native keychain"; exit 1`,
+		"fail":     `echo "error getting credentials"; exit 1`,
+		"garbage":  `echo "not json"`,
+	}
+	for name, script := range helpers {
+		if err := os.WriteFile(filepath.Join(helperDir, "docker-credential-"+name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", helperDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected authorization header")
+		}
+		w.Write([]byte(`{"tags":["1.0"]}`))
+	}))
+	defer ts.Close()
+
+	defaultClient := http.DefaultClient
+	http.DefaultClient = ts.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+
+	host := strings.TrimPrefix(ts.URL, "https://")
+	testCases := map[string]string{
+		"broken config":  `{"auths": `,
+		"missing helper": `{"credsStore": "bump-missing-helper"}`,
+		"not found":      `{"credsStore": "notfound"}`,
+		"failing helper": `{"credHelpers": {"` + host + `": "fail"}}`,
+		"garbage helper": `{"credsStore": "garbage"}`,
+		"invalid auths":  `{"auths": {"` + host + `": {"auth": "%%%"}}}`,
+	}
+	for name, config := range testCases {
+		t.Run(name, func(t *testing.T) {
+			writeConfig(t, config)
+			r, err := dockerv2.NewFromImage(host + "/org/image")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tags, err := r.Tags()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tags) != 1 || tags[0] != "1.0" {
+				t.Fatalf("unexpected tags %v", tags)
+			}
+		})
+	}
+}
+
+func TestCredentialsToTokenRealm(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"abc"}`))
+		case "/v2/private/image/tags/list":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+ts.URL+`/token",service="test",scope="repository:private/image:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"tags":["1.0"]}`))
+		case "/v2/basic/image/tags/list":
+			if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"tags":["2.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	defaultClient := http.DefaultClient
+	http.DefaultClient = ts.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+
+	host := strings.TrimPrefix(ts.URL, "https://")
+	// user:pass
+	writeConfig(t, `{"auths": {"`+host+`": {"auth": "dXNlcjpwYXNz"}}}`)
+
+	for image, expected := range map[string]string{
+		"private/image": "1.0",
+		"basic/image":   "2.0",
+	} {
+		r, err := dockerv2.NewFromImage(host + "/" + image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tags, err := r.Tags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != 1 || tags[0] != expected {
+			t.Fatalf("%s: unexpected tags %v", image, tags)
+		}
+	}
+}
+
+func TestCredentialsErrorInAuthError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script credential helper")
+	}
+
+	helperDir := t.TempDir()
+	helper := "#!/bin/sh\necho \"error getting credentials - err: token expired\"; exit 1\n"
+	if err := os.WriteFile(filepath.Join(helperDir, "docker-credential-fail"), []byte(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", helperDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/private/image/tags/list":
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	defaultClient := http.DefaultClient
+	http.DefaultClient = ts.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+
+	host := strings.TrimPrefix(ts.URL, "https://")
+	writeConfig(t, `{"credHelpers": {"`+host+`": "fail"}}`)
+
+	for image, expected := range map[string]string{
+		"private/image": "401 Unauthorized: basic auth required but no credentials found (anonymous access used as credentials failed: ",
+		"missing/image": "404 Not Found",
+	} {
+		r, err := dockerv2.NewFromImage(host + "/" + image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = r.Tags()
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("%s: expected error starting with %q got %v", image, expected, err)
+		}
+		if image == "private/image" && !strings.Contains(err.Error(), "token expired") {
+			t.Fatalf("%s: expected helper error got %v", image, err)
+		}
+	}
+}
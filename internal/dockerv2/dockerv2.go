@@ -1,45 +1,72 @@
 package dockerv2
 
 import (
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 )
 
 type Registry struct {
 	Host  string
 	Image string
 	Token string
-}
 
-var defaultRegistry = Registry{
-	Host: "index.docker.io",
+	mu                sync.Mutex
+	credentials       *Credentials
+	credentialsErr    error
+	credentialsLoaded bool
+	// authorization header from last successful auth reused for following requests
+	authHeaderValue string
 }
 
+const defaultRegistryHost = "index.docker.io"
+
 const listTagsURLTemplate = `https://%s/v2/%s/tags/list`
+const manifestURLTemplate = `https://%s/v2/%s/manifests/%s`
+const blobURLTemplate = `https://%s/v2/%s/blobs/%s`
+
+// Media types for manifests and indexes
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+var manifestAccept = strings.Join([]string{
+	MediaTypeOCIIndex,
+	MediaTypeDockerManifestList,
+	MediaTypeOCIManifest,
+	MediaTypeDockerManifest,
+}, ", ")
 
 func NewFromImage(image string) (*Registry, error) {
 	parts := strings.Split(image, "/")
-	r := defaultRegistry
+	r := &Registry{Host: defaultRegistryHost}
 	switch {
 	case len(parts) == 0:
-		return &r, fmt.Errorf("invalid image")
+		return r, fmt.Errorf("invalid image")
 	case len(parts) == 1:
 		// image
 		r.Image = "library/" + image
-		return &r, nil
+		return r, nil
 	case strings.Contains(parts[0], "."):
 		// host.tldr/image
 		r.Host = parts[0]
 		r.Image = strings.Join(parts[1:], "/")
-		return &r, nil
+		return r, nil
 	default:
 		// repo/image
 		r.Image = image
-		return &r, nil
+		return r, nil
 	}
 }
 
@@ -137,7 +164,8 @@ func ParseLinkHeader(s string) ([]LinkHeaderPart, error) {
 }
 
 type authRespBody struct {
-	Token string `json:"token"`
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
 }
 
 type getResp[T any] struct {
@@ -146,14 +174,23 @@ type getResp[T any] struct {
 	NextRawURL string
 }
 
-func get[T any](rawURL string, doAuth bool, authHeader string) (getResp[T], error) {
-	var resp getResp[T]
+// statusError is a 4xx response, error message is the status
+type statusError struct {
+	StatusCode int
+	Status     string
+}
 
-	resp.AuthHeader = authHeader
+func (e statusError) Error() string { return e.Status }
 
-	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+// do request and handle bearer token or basic auth, caller should close body
+// credentials are sent to the token realm or directly if basic auth
+func do(method string, rawURL string, header http.Header, doAuth bool, authHeader string, creds *Credentials) (*http.Response, string, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
 	if err != nil {
-		return resp, err
+		return nil, "", err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
 	}
 
 	if authHeader != "" {
@@ -162,21 +199,33 @@ func get[T any](rawURL string, doAuth bool, authHeader string) (getResp[T], erro
 
 	r, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return resp, fmt.Errorf("request failed: %w", err)
+		return nil, "", fmt.Errorf("request failed: %w", err)
 	}
-	defer r.Body.Close()
 
 	// 4xx some client error
 	if r.StatusCode/100 == 4 {
+		r.Body.Close()
 		if doAuth && r.StatusCode == http.StatusUnauthorized {
 			wwwAuth := r.Header.Get("WWW-Authenticate")
 			if wwwAuth == "" {
-				return resp, fmt.Errorf("no WWW-Authenticate found")
+				return nil, "", fmt.Errorf("no WWW-Authenticate found")
 			}
 
 			w, wwwAuthErr := ParseWWWAuth(wwwAuth)
 			if wwwAuthErr != nil {
-				return resp, wwwAuthErr
+				return nil, "", wwwAuthErr
+			}
+
+			if strings.EqualFold(w.Scheme, "Basic") {
+				if creds == nil {
+					return nil, "", fmt.Errorf("%w: basic auth required but no credentials found", statusError{StatusCode: r.StatusCode, Status: r.Status})
+				}
+				return do(method, rawURL, header, false, creds.BasicAuthHeader(), nil)
+			}
+
+			tokenAuthHeader := ""
+			if creds != nil {
+				tokenAuthHeader = creds.BasicAuthHeader()
 			}
 
 			authURLValues := url.Values{}
@@ -184,25 +233,44 @@ func get[T any](rawURL string, doAuth bool, authHeader string) (getResp[T], erro
 			authURLValues.Set("scope", w.Params["scope"])
 			authURL, authURLErr := url.Parse(w.Params["realm"])
 			if authURLErr != nil {
-				return resp, authURLErr
+				return nil, "", authURLErr
 			}
 			authURL.RawQuery = authURLValues.Encode()
 
-			authResp, authTokenErr := get[authRespBody](authURL.String(), false, "")
+			authResp, authTokenErr := get[authRespBody](authURL.String(), false, tokenAuthHeader, nil)
 			if authTokenErr != nil {
-				return resp, authTokenErr
+				return nil, "", fmt.Errorf("token: %w", authTokenErr)
+			}
+			token := authResp.Body.Token
+			if token == "" {
+				token = authResp.Body.AccessToken
 			}
 
-			return get[T](rawURL, false, fmt.Sprintf("Bearer %s", authResp.Body.Token))
+			return do(method, rawURL, header, false, fmt.Sprintf("Bearer %s", token), nil)
 		}
-		return resp, fmt.Errorf(r.Status)
+		return nil, "", statusError{StatusCode: r.StatusCode, Status: r.Status}
 	}
 
 	// not 2xx success
 	if r.StatusCode/100 != 2 {
-		return resp, fmt.Errorf("error response: %s", r.Status)
+		r.Body.Close()
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
 	}
 
+	return r, authHeader, nil
+}
+
+func get[T any](rawURL string, doAuth bool, authHeader string, creds *Credentials) (getResp[T], error) {
+	var resp getResp[T]
+
+	r, authHeader, err := do(http.MethodGet, rawURL, nil, doAuth, authHeader, creds)
+	if err != nil {
+		return resp, err
+	}
+	defer r.Body.Close()
+
+	resp.AuthHeader = authHeader
+
 	if link := r.Header.Get("Link"); link != "" {
 		parts, partsErr := ParseLinkHeader(link)
 		if partsErr != nil {
@@ -227,22 +295,22 @@ type respBody struct {
 	Tags []string `json:"tags"`
 }
 
-func getPaged[T any](rawURL string, doAuth bool, token string) ([]T, error) {
+func getPaged[T any](rawURL string, doAuth bool, authHeader string, creds *Credentials) ([]T, string, error) {
 	var vs []T
 
 	u, uErr := url.Parse(rawURL)
 	if uErr != nil {
-		return nil, uErr
+		return nil, "", uErr
 	}
 
-	authHeader := ""
 	const maxNext = 1000
 
 	for i := 0; true; i++ {
-		resp, err := get[T](rawURL, true, authHeader)
+		resp, err := get[T](rawURL, doAuth, authHeader, creds)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		authHeader = resp.AuthHeader
 		vs = append(vs, resp.Body)
 
 		if resp.NextRawURL == "" {
@@ -251,24 +319,25 @@ func getPaged[T any](rawURL string, doAuth bool, token string) ([]T, error) {
 
 		nextURL, nextURLErr := url.Parse(resp.NextRawURL)
 		if nextURLErr != nil {
-			return nil, nextURLErr
+			return nil, "", nextURLErr
 		}
 		rawURL = u.ResolveReference(nextURL).String()
-		authHeader = resp.AuthHeader
 
 		if i > maxNext {
-			return nil, fmt.Errorf("max next links (%d) reached", maxNext)
+			return nil, "", fmt.Errorf("max next links (%d) reached", maxNext)
 		}
 	}
 
-	return vs, nil
+	return vs, authHeader, nil
 }
 
 func (r *Registry) Tags() ([]string, error) {
-	resps, err := getPaged[respBody](fmt.Sprintf(listTagsURLTemplate, r.Host, r.Image), true, "")
+	creds := r.loadCredentials()
+	resps, authHeader, err := getPaged[respBody](fmt.Sprintf(listTagsURLTemplate, r.Host, r.Image), true, r.authHeader(), creds)
 	if err != nil {
-		return nil, err
+		return nil, r.credentialsError(err)
 	}
+	r.setAuthHeader(authHeader)
 
 	var tags []string
 	for _, resp := range resps {
@@ -277,3 +346,298 @@ func (r *Registry) Tags() ([]string, error) {
 
 	return tags, nil
 }
+
+// Platform of a image manifest in a index
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Descriptor points to content by digest
+type Descriptor struct {
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Manifest is a image manifest or a index (manifest list)
+type Manifest struct {
+	MediaType string `json:"mediaType"`
+	// Digest is from Docker-Content-Digest header or calculated from body
+	Digest    string       `json:"-"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// IsIndex is true for multi-platform indexes and manifest lists
+func (m Manifest) IsIndex() bool {
+	return m.MediaType == MediaTypeOCIIndex ||
+		m.MediaType == MediaTypeDockerManifestList ||
+		(m.MediaType == "" && len(m.Manifests) > 0)
+}
+
+// loadCredentials from docker config once. Anonymous access is used if config
+// or credential helper fails, public images should still work with a broken or
+// unusual docker config. The error is kept and added to auth errors.
+func (r *Registry) loadCredentials() *Credentials {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.credentialsLoaded {
+		return r.credentials
+	}
+	creds, err := ConfigCredentials(r.Host)
+	if err != nil {
+		creds = nil
+	}
+	r.credentials = creds
+	r.credentialsErr = err
+	r.credentialsLoaded = true
+	return creds
+}
+
+// credentialsError adds docker config error to 401 and 403 errors as it is
+// probably the reason, ex an expired credential helper login
+func (r *Registry) credentialsError(err error) error {
+	r.mu.Lock()
+	credsErr := r.credentialsErr
+	r.mu.Unlock()
+
+	var se statusError
+	if credsErr == nil || !errors.As(err, &se) ||
+		(se.StatusCode != http.StatusUnauthorized && se.StatusCode != http.StatusForbidden) {
+		return err
+	}
+	return fmt.Errorf("%w (anonymous access used as credentials failed: %v)", err, credsErr)
+}
+
+// normalize platform, arm64 without variant is v8
+func (p Platform) normalize() Platform {
+	if p.Architecture == "arm64" && p.Variant == "" {
+		p.Variant = "v8"
+	}
+	return p
+}
+
+// ParsePlatform parses "os/arch[/variant]"
+func ParsePlatform(s string) (Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Platform{}, fmt.Errorf("platform should be <os/arch[/variant]>: %s", s)
+	}
+	p := Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// PlatformManifest finds manifest for a platform like "linux/arm64/v8" in a
+// index. Platform without variant matches any variant, prefers exact match and
+// arm64 without variant is same as arm64/v8. If platform is empty linux/amd64
+// is preferred otherwise first image.
+func (m Manifest) PlatformManifest(platform string) (Descriptor, bool) {
+	if platform != "" {
+		want, err := ParsePlatform(platform)
+		if err != nil {
+			return Descriptor{}, false
+		}
+		anyVariant := want.Variant == ""
+		want = want.normalize()
+		var match *Descriptor
+		for i, d := range m.Manifests {
+			if d.Platform == nil {
+				continue
+			}
+			p := d.Platform.normalize()
+			if p.OS != want.OS || p.Architecture != want.Architecture {
+				continue
+			}
+			if p.Variant == want.Variant {
+				return d, true
+			}
+			if anyVariant && match == nil {
+				match = &m.Manifests[i]
+			}
+		}
+		if match != nil {
+			return *match, true
+		}
+		return Descriptor{}, false
+	}
+
+	var first *Descriptor
+	for i, d := range m.Manifests {
+		if d.Platform == nil {
+			continue
+		}
+		if d.Platform.String() == "linux/amd64" {
+			return d, true
+		}
+		// attestations manifests has platform unknown/unknown
+		if first == nil && d.Platform.OS != "unknown" {
+			first = &m.Manifests[i]
+		}
+	}
+	if first != nil {
+		return *first, true
+	}
+	return Descriptor{}, false
+}
+
+// ImageConfig is the parts used of a image config blob
+type ImageConfig struct {
+	Created string `json:"created"`
+	Config  struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+func (r *Registry) authHeader() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.authHeaderValue
+}
+
+func (r *Registry) setAuthHeader(authHeader string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.authHeaderValue = authHeader
+}
+
+func (r *Registry) doManifest(method string, reference string) (*http.Response, error) {
+	creds := r.loadCredentials()
+	resp, authHeader, err := do(
+		method,
+		fmt.Sprintf(manifestURLTemplate, r.Host, r.Image, reference),
+		http.Header{"Accept": []string{manifestAccept}},
+		true,
+		r.authHeader(),
+		creds,
+	)
+	if err != nil {
+		return nil, r.credentialsError(err)
+	}
+	r.setAuthHeader(authHeader)
+	return resp, nil
+}
+
+// ManifestDescriptor does a HEAD request for a tag or digest and returns
+// media type and digest without fetching the manifest
+func (r *Registry) ManifestDescriptor(reference string) (Descriptor, error) {
+	resp, err := r.doManifest(http.MethodHead, reference)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	resp.Body.Close()
+
+	d := Descriptor{
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    resp.Header.Get("Docker-Content-Digest"),
+		Size:      resp.ContentLength,
+	}
+	// some registries don't return digest for HEAD, fetch manifest instead
+	if d.Digest == "" {
+		m, err := r.Manifest(reference)
+		if err != nil {
+			return Descriptor{}, err
+		}
+		d.Digest = m.Digest
+	}
+
+	return d, nil
+}
+
+// Manifest fetches a manifest or index for a tag or digest
+func (r *Registry) Manifest(reference string) (Manifest, error) {
+	resp, err := r.doManifest(http.MethodGet, reference)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return Manifest{}, fmt.Errorf("failed parse manifest: %w", err)
+	}
+	if m.MediaType == "" {
+		m.MediaType = resp.Header.Get("Content-Type")
+	}
+	m.Digest = resp.Header.Get("Docker-Content-Digest")
+	if m.Digest == "" {
+		h := sha256.Sum256(b)
+		m.Digest = "sha256:" + hex.EncodeToString(h[:])
+	}
+
+	return m, nil
+}
+
+// Image is a resolved image
+type Image struct {
+	// Digest of manifest, for a multi-platform image the digest of the index
+	Digest string
+	// PlatformDigest is digest of the platform image manifest, same as Digest
+	// for single platform images
+	PlatformDigest string
+	Config         ImageConfig
+}
+
+// ResolveImage resolves digests and fetches image config blob for a tag or digest.
+// For a multi-platform image platform is used, see PlatformManifest.
+func (r *Registry) ResolveImage(reference string, platform string) (Image, error) {
+	m, err := r.Manifest(reference)
+	if err != nil {
+		return Image{}, err
+	}
+	img := Image{Digest: m.Digest, PlatformDigest: m.Digest}
+	if m.IsIndex() {
+		d, ok := m.PlatformManifest(platform)
+		if !ok {
+			return Image{}, fmt.Errorf("no image found for platform %q", platform)
+		}
+		img.PlatformDigest = d.Digest
+		if m, err = r.Manifest(d.Digest); err != nil {
+			return Image{}, err
+		}
+	}
+	if m.Config.Digest == "" {
+		return Image{}, fmt.Errorf("manifest has no config")
+	}
+
+	creds := r.loadCredentials()
+	// blobs are usually redirected to a CDN, authorization header is not
+	// forwarded by http.Client to other hosts
+	resp, authHeader, err := do(
+		http.MethodGet,
+		fmt.Sprintf(blobURLTemplate, r.Host, r.Image, m.Config.Digest),
+		nil,
+		true,
+		r.authHeader(),
+		creds,
+	)
+	if err != nil {
+		return Image{}, r.credentialsError(err)
+	}
+	defer resp.Body.Close()
+	r.setAuthHeader(authHeader)
+
+	if err := json.NewDecoder(resp.Body).Decode(&img.Config); err != nil {
+		return Image{}, fmt.Errorf("failed parse image config: %w", err)
+	}
+
+	return img, nil
+}
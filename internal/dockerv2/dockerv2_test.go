@@ -1,6 +1,11 @@
 package dockerv2_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/wader/bump/internal/dockerv2"
@@ -38,3 +43,160 @@ func TestParseLinkHeader(t *testing.T) {
 		t.Fatalf("expected Params[rel] %s, got %s", expectedParamsRel, actualParamsRel)
 	}
 }
+
+func TestManifest(t *testing.T) {
+	const index = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+		`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:amd64","size":1,"platform":{"architecture":"amd64","os":"linux"}},` +
+		`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:arm64v8","size":1,"platform":{"architecture":"arm64","os":"linux","variant":"v8"}}]}`
+	tokenRequests := 0
+
+	var ts *httptest.Server
+	ts = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			if r.URL.Query().Get("scope") != "repository:org/image:pull" {
+				t.Errorf("unexpected scope %q", r.URL.Query().Get("scope"))
+			}
+			w.Write([]byte(`{"token":"abc"}`))
+			return
+		case "/v2/org/image/manifests/1.0", "/v2/org/image/manifests/sha256:index":
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+ts.URL+`/token",service="test",scope="repository:org/image:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+		if r.URL.Path == "/v2/org/image/manifests/1.0" {
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+		}
+		w.Write([]byte(index))
+	}))
+	defer ts.Close()
+
+	defaultClient := http.DefaultClient
+	http.DefaultClient = ts.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+	// no credentials
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	r, err := dockerv2.NewFromImage(strings.TrimPrefix(ts.URL, "https://") + "/org/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := r.ManifestDescriptor("1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Digest != "sha256:index" || d.MediaType != dockerv2.MediaTypeOCIIndex {
+		t.Fatalf("unexpected descriptor %#v", d)
+	}
+
+	m, err := r.Manifest("sha256:index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256([]byte(index))
+	if expected := "sha256:" + hex.EncodeToString(h[:]); m.Digest != expected {
+		t.Fatalf("expected calculated digest %s got %s", expected, m.Digest)
+	}
+	if !m.IsIndex() || len(m.Manifests) != 2 || m.Manifests[1].Platform.String() != "linux/arm64/v8" {
+		t.Fatalf("unexpected manifest %#v", m)
+	}
+	if tokenRequests != 1 {
+		t.Fatalf("expected one token request got %d", tokenRequests)
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	const index = `{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+		`{"digest":"sha256:att","platform":{"architecture":"unknown","os":"unknown"}},` +
+		`{"digest":"sha256:arm64","platform":{"architecture":"arm64","os":"linux"}},` +
+		`{"digest":"sha256:amd64","platform":{"architecture":"amd64","os":"linux"}}]}`
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/image/manifests/1.0":
+			w.Header().Set("Content-Type", dockerv2.MediaTypeOCIIndex)
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			w.Write([]byte(index))
+		case "/v2/org/image/manifests/sha256:amd64", "/v2/org/image/manifests/sha256:arm64":
+			arch := strings.TrimPrefix(r.URL.Path, "/v2/org/image/manifests/sha256:")
+			w.Header().Set("Content-Type", dockerv2.MediaTypeOCIManifest)
+			w.Write([]byte(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"digest":"sha256:config` + arch + `"}}`))
+		case "/v2/org/image/blobs/sha256:configamd64", "/v2/org/image/blobs/sha256:configarm64":
+			// like a redirect to CDN
+			http.Redirect(w, r, "/cdn/"+strings.TrimPrefix(r.URL.Path, "/v2/org/image/blobs/"), http.StatusTemporaryRedirect)
+		case "/cdn/sha256:configamd64", "/cdn/sha256:configarm64":
+			arch := strings.TrimPrefix(r.URL.Path, "/cdn/sha256:config")
+			w.Write([]byte(`{"created":"2023-01-01T00:00:00Z","config":{"Labels":{"org.opencontainers.image.revision":"` + arch + `"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	defaultClient := http.DefaultClient
+	http.DefaultClient = ts.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	r, err := dockerv2.NewFromImage(strings.TrimPrefix(ts.URL, "https://") + "/org/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for platform, expected := range map[string]string{
+		"":               "amd64",
+		"linux/amd64":    "amd64",
+		"linux/arm64":    "arm64",
+		"linux/arm64/v8": "arm64",
+	} {
+		img, err := r.ResolveImage("1.0", platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Digest != "sha256:index" || img.PlatformDigest != "sha256:"+expected {
+			t.Fatalf("%s: unexpected digests %s %s", platform, img.Digest, img.PlatformDigest)
+		}
+		if img.Config.Created != "2023-01-01T00:00:00Z" || img.Config.Config.Labels["org.opencontainers.image.revision"] != expected {
+			t.Fatalf("%s: unexpected config %#v", platform, img.Config)
+		}
+	}
+
+	for _, platform := range []string{"linux/s390x", "linux/arm64/v7"} {
+		if _, err := r.ResolveImage("1.0", platform); err == nil || err.Error() != `no image found for platform "`+platform+`"` {
+			t.Fatalf("%s: expected platform error got %v", platform, err)
+		}
+	}
+}
+
+func TestPlatformManifest(t *testing.T) {
+	m := dockerv2.Manifest{Manifests: []dockerv2.Descriptor{
+		{Digest: "sha256:att", Platform: &dockerv2.Platform{OS: "unknown", Architecture: "unknown"}},
+		{Digest: "sha256:armv6", Platform: &dockerv2.Platform{OS: "linux", Architecture: "arm", Variant: "v6"}},
+		{Digest: "sha256:armv7", Platform: &dockerv2.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+		{Digest: "sha256:arm64v8", Platform: &dockerv2.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+	}}
+
+	for platform, expected := range map[string]string{
+		"":               "sha256:armv6",
+		"linux/arm":      "sha256:armv6",
+		"linux/arm/v7":   "sha256:armv7",
+		"linux/arm64":    "sha256:arm64v8",
+		"linux/arm64/v8": "sha256:arm64v8",
+		"linux/arm/v5":   "",
+		"linux/amd64":    "",
+		"linux":          "",
+	} {
+		d, ok := m.PlatformManifest(platform)
+		if ok != (expected != "") || d.Digest != expected {
+			t.Errorf("%q: expected %q got %q %v", platform, expected, d.Digest, ok)
+		}
+	}
+}
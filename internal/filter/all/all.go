@@ -2,18 +2,41 @@ package all
 
 import (
 	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/apk"
+	"github.com/wader/bump/internal/filter/conda"
+	"github.com/wader/bump/internal/filter/crates"
+	"github.com/wader/bump/internal/filter/deb"
+	"github.com/wader/bump/internal/filter/debver"
 	"github.com/wader/bump/internal/filter/depsdev"
 	"github.com/wader/bump/internal/filter/docker"
+	"github.com/wader/bump/internal/filter/dockerinfo"
+	"github.com/wader/bump/internal/filter/eol"
 	"github.com/wader/bump/internal/filter/err"
+	"github.com/wader/bump/internal/filter/feed"
 	"github.com/wader/bump/internal/filter/fetch"
 	"github.com/wader/bump/internal/filter/git"
+	"github.com/wader/bump/internal/filter/gitbranch"
+	"github.com/wader/bump/internal/filter/github"
+	"github.com/wader/bump/internal/filter/gitlab"
 	"github.com/wader/bump/internal/filter/gitrefs"
+	"github.com/wader/bump/internal/filter/goproxy"
+	"github.com/wader/bump/internal/filter/helm"
+	"github.com/wader/bump/internal/filter/hg"
+	"github.com/wader/bump/internal/filter/index"
+	"github.com/wader/bump/internal/filter/json"
 	"github.com/wader/bump/internal/filter/key"
+	"github.com/wader/bump/internal/filter/maven"
+	"github.com/wader/bump/internal/filter/npm"
+	"github.com/wader/bump/internal/filter/packagist"
+	"github.com/wader/bump/internal/filter/pypi"
 	"github.com/wader/bump/internal/filter/re"
+	"github.com/wader/bump/internal/filter/rubygems"
 	"github.com/wader/bump/internal/filter/semver"
 	"github.com/wader/bump/internal/filter/sort"
 	"github.com/wader/bump/internal/filter/static"
 	"github.com/wader/bump/internal/filter/svn"
+	"github.com/wader/bump/internal/filter/xpath"
+	"github.com/wader/bump/internal/filter/yaml"
 )
 
 // Filters return all filters
@@ -21,11 +44,34 @@ func Filters() []filter.NamedFilter {
 	return []filter.NamedFilter{
 		{Name: git.Name, Help: git.Help, NewFn: git.New}, // before fetch to let it get URLs ending with .git
 		{Name: gitrefs.Name, Help: gitrefs.Help, NewFn: gitrefs.New},
+		{Name: gitbranch.Name, Help: gitbranch.Help, NewFn: gitbranch.New},
+		{Name: hg.Name, Help: hg.Help, NewFn: hg.New},
+		{Name: github.Name, Help: github.Help, NewFn: github.New},
+		{Name: gitlab.Name, Help: gitlab.Help, NewFn: gitlab.New},
 		{Name: depsdev.Name, Help: depsdev.Help, NewFn: depsdev.New},
+		{Name: eol.Name, Help: eol.Help, NewFn: eol.New},
+		{Name: npm.Name, Help: npm.Help, NewFn: npm.New},
+		{Name: pypi.Name, Help: pypi.Help, NewFn: pypi.New},
+		{Name: conda.Name, Help: conda.Help, NewFn: conda.New},
+		{Name: packagist.Name, Help: packagist.Help, NewFn: packagist.New},
+		{Name: rubygems.Name, Help: rubygems.Help, NewFn: rubygems.New},
+		{Name: crates.Name, Help: crates.Help, NewFn: crates.New},
+		{Name: goproxy.Name, Help: goproxy.Help, NewFn: goproxy.New},
+		{Name: maven.Name, Help: maven.Help, NewFn: maven.New},
+		{Name: helm.Name, Help: helm.Help, NewFn: helm.New},
+		{Name: apk.Name, Help: apk.Help, NewFn: apk.New},
+		{Name: deb.Name, Help: deb.Help, NewFn: deb.New},
 		{Name: docker.Name, Help: docker.Help, NewFn: docker.New},
+		{Name: dockerinfo.Name, Help: dockerinfo.Help, NewFn: dockerinfo.New},
 		{Name: svn.Name, Help: svn.Help, NewFn: svn.New},
 		{Name: fetch.Name, Help: fetch.Help, NewFn: fetch.New},
+		{Name: index.Name, Help: index.Help, NewFn: index.New},
+		{Name: feed.Name, Help: feed.Help, NewFn: feed.New},
+		{Name: json.Name, Help: json.Help, NewFn: json.New},
+		{Name: yaml.Name, Help: yaml.Help, NewFn: yaml.New},
+		{Name: xpath.Name, Help: xpath.Help, NewFn: xpath.New},
 		{Name: semver.Name, Help: semver.Help, NewFn: semver.New},
+		{Name: debver.Name, Help: debver.Help, NewFn: debver.New},
 		{Name: re.Name, Help: re.Help, NewFn: re.New},
 		{Name: sort.Name, Help: sort.Help, NewFn: sort.New},
 		{Name: key.Name, Help: key.Help, NewFn: key.New},
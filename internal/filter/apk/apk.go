@@ -0,0 +1,167 @@
+package apk
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// APKINDEX.tar.gz is a signature and an index gzip stream concatenated
+// APKINDEX has one record per package separated by a blank line
+// https://wiki.alpinelinux.org/wiki/Apk_spec#APKINDEX_Format
+/*
+C:Q1...=
+P:curl
+V:8.5.0-r0
+A:x86_64
+S:208016
+I:389120
+T:URL retrival utility and library
+U:https://curl.se/
+L:curl
+o:curl
+m:Natanael Copa <ncopa@alpinelinux.org>
+t:1701935130
+c:4a3a6d4b5b05e1e1f1c3d0e3d4b6b4a8c5fa8a3e
+D:ca-certificates so:libc.musl-x86_64.so.1 so:libcurl.so.4 so:libz.so.1
+p:cmd:curl=8.5.0-r0
+*/
+
+// Name of filter
+const Name = "apk"
+
+// Help text
+var Help = `
+apk:<mirror>/<branch>/<repo>/<arch>:<package>
+
+Produce versions for an Alpine package from a APKINDEX. Name will be the
+version without release like "8.5.0" so that semver constraints work. Other
+keys are version (version with release like "8.5.0-r0"), pkgver (same as
+name), pkgrel (release number), build_time, origin and commit.
+
+apk:https://dl-cdn.alpinelinux.org/alpine/v3.19/main/x86_64:curl|^8
+apk:https://dl-cdn.alpinelinux.org/alpine/v3.19/main/x86_64:curl|^8|@version
+`[1:]
+
+// New apk filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a repository and package")
+	}
+
+	n := strings.LastIndex(arg, ":")
+	if n == -1 || !strings.Contains(arg[0:n], "://") || arg[n+1:] == "" {
+		return nil, fmt.Errorf("should be apk:<mirror>/<branch>/<repo>/<arch>:<package>")
+	}
+
+	return apkFilter{
+		repo:     strings.TrimSuffix(arg[0:n], "/"),
+		package_: arg[n+1:],
+	}, nil
+}
+
+type apkFilter struct {
+	repo     string
+	package_ string
+}
+
+func (f apkFilter) String() string {
+	return Name + ":" + f.repo + ":" + f.package_
+}
+
+// readIndex finds the APKINDEX file in APKINDEX.tar.gz
+func readIndex(r io.Reader) (io.Reader, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no APKINDEX found")
+		} else if err != nil {
+			return nil, err
+		}
+		if h.Name == "APKINDEX" {
+			return tr, nil
+		}
+	}
+}
+
+func (f apkFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	r, err := http.Get(f.repo + "/APKINDEX.tar.gz")
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	ir, err := readIndex(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	addRecord := func(fields map[string]string) {
+		if fields["P"] != f.package_ {
+			return
+		}
+		v := fields["V"]
+		// 8.5.0-r0 -> 8.5.0, 0
+		pkgver, pkgrel := v, ""
+		if n := strings.LastIndex(v, "-r"); n != -1 {
+			pkgver, pkgrel = v[0:n], v[n+2:]
+		}
+		var buildTime string
+		if t, err := strconv.ParseInt(fields["t"], 10, 64); err == nil {
+			buildTime = time.Unix(t, 0).UTC().Format(time.RFC3339)
+		}
+
+		vs = append(vs, filter.NewVersionWithName(pkgver, map[string]string{
+			"version":    v,
+			"pkgver":     pkgver,
+			"pkgrel":     pkgrel,
+			"build_time": buildTime,
+			"origin":     fields["o"],
+			"commit":     fields["c"],
+		}))
+	}
+
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(ir)
+	for scanner.Scan() {
+		l := scanner.Text()
+		if l == "" {
+			addRecord(fields)
+			fields = map[string]string{}
+			continue
+		}
+		k, v, ok := strings.Cut(l, ":")
+		if !ok {
+			continue
+		}
+		fields[k] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	addRecord(fields)
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,119 @@
+package apk_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/apk"
+)
+
+// writeSegment writes a gzip compressed tar segment, signature segment is not
+// terminated so that segments concatenate into one tar stream
+func writeSegment(t *testing.T, w *bytes.Buffer, files map[string]string, names []string, terminate bool) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, name := range names {
+		data := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var err error
+	if terminate {
+		err = tw.Close()
+	} else {
+		err = tw.Flush()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	index := &bytes.Buffer{}
+	writeSegment(t, index, map[string]string{
+		".SIGN.RSA.test.rsa.pub": "signature",
+	}, []string{".SIGN.RSA.test.rsa.pub"}, false)
+	writeSegment(t, index, map[string]string{
+		"DESCRIPTION": "v3.19.0",
+		"APKINDEX": "" +
+			"C:Q1aaa=\n" +
+			"P:curl\n" +
+			"V:8.5.0-r0\n" +
+			"A:x86_64\n" +
+			"o:curl\n" +
+			"t:1701935130\n" +
+			"c:4a3a6d4b5b05e1e1f1c3d0e3d4b6b4a8c5fa8a3e\n" +
+			"\n" +
+			"C:Q1bbb=\n" +
+			"P:curl-dev\n" +
+			"V:8.5.0-r0\n" +
+			"o:curl\n" +
+			"\n" +
+			"C:Q1ccc=\n" +
+			"P:curl\n" +
+			"V:7.88.1-r12\n" +
+			"o:curl\n" +
+			"t:1690000000\n" +
+			"c:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb\n",
+	}, []string{"DESCRIPTION", "APKINDEX"}, true)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/alpine/v3.19/main/x86_64/APKINDEX.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(index.Bytes())
+	}))
+	defer ts.Close()
+
+	f, err := apk.New(apk.Name, ts.URL+"/alpine/v3.19/main/x86_64/:curl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{
+			"name":       "8.5.0",
+			"version":    "8.5.0-r0",
+			"pkgver":     "8.5.0",
+			"pkgrel":     "0",
+			"build_time": "2023-12-07T07:45:30Z",
+			"origin":     "curl",
+			"commit":     "4a3a6d4b5b05e1e1f1c3d0e3d4b6b4a8c5fa8a3e",
+		},
+		{
+			"name":       "7.88.1",
+			"version":    "7.88.1-r12",
+			"pkgver":     "7.88.1",
+			"pkgrel":     "12",
+			"build_time": "2023-07-22T04:26:40Z",
+			"origin":     "curl",
+			"commit":     "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	f, err = apk.New(apk.Name, ts.URL+"/alpine/v3.19/main/missing:curl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != "error response: 404 Not Found" {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package conda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://conda.anaconda.org/conda-forge/linux-64/repodata.json
+// packages.conda has the same format for .conda packages
+/*
+{
+  "info": {"subdir": "linux-64"},
+  "packages": {
+    "numpy-1.26.2-py312heda63a1_0.tar.bz2": {
+      "build": "py312heda63a1_0",
+      "build_number": 0,
+      "depends": [...],
+      "name": "numpy",
+      "timestamp": 1700645486543,
+      "version": "1.26.2",
+      ...
+    },
+    ...
+  },
+  "packages.conda": {...}
+}
+*/
+
+const defaultChannelURL = "https://conda.anaconda.org"
+
+// Name of filter
+const Name = "conda"
+
+// Help text
+var Help = `
+conda:<channel>/<subdir>:<package> or conda:<channel>/<subdir>:<package>,current
+
+Produce versions from a conda channel repodata.json. Name will be the version.
+Other keys are build, build_number and timestamp (RFC3339 UTC, empty if
+unknown). There is one version per build so same version can appear more
+than once.
+
+Versions are ordered by timestamp, newest first. Channel can be a name like
+conda-forge at https://conda.anaconda.org or a URL. With current option the
+smaller current_repodata.json with only the latest versions is used.
+
+conda:conda-forge/linux-64:numpy|^1
+conda:conda-forge/noarch:requests,current|*
+conda:https://conda.anaconda.org/conda-forge/linux-64:python|^3.12|@build
+`[1:]
+
+// New conda filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a channel, subdir and package")
+	}
+
+	parts := strings.Split(arg, ",")
+	var f condaFilter
+	for _, o := range parts[1:] {
+		switch o {
+		case "current":
+			f.current = true
+		default:
+			return nil, fmt.Errorf("unknown option: %s", o)
+		}
+	}
+
+	arg = parts[0]
+	n := strings.LastIndex(arg, ":")
+	if n == -1 || arg[n+1:] == "" || !strings.Contains(arg[0:n], "/") {
+		return nil, fmt.Errorf("should be conda:<channel>/<subdir>:<package>")
+	}
+	f.channelSubdir = strings.TrimSuffix(arg[0:n], "/")
+	f.package_ = arg[n+1:]
+
+	return f, nil
+}
+
+type condaFilter struct {
+	channelSubdir string
+	package_      string
+	current       bool
+}
+
+func (f condaFilter) String() string {
+	s := Name + ":" + f.channelSubdir + ":" + f.package_
+	if f.current {
+		s += ",current"
+	}
+	return s
+}
+
+type record struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Build       string `json:"build"`
+	BuildNumber int    `json:"build_number"`
+	Timestamp   int64  `json:"timestamp"` // milliseconds, seconds in some old packages
+}
+
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != d {
+		return fmt.Errorf("expected %s got %v", d, t)
+	}
+	return nil
+}
+
+// readRecords reads records for a package from repodata.json
+// decodes one package at a time as repodata.json can be huge
+func readRecords(r io.Reader, package_ string) ([]record, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var records []record
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if t != "packages" && t != "packages.conda" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			// filename
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			var rec record
+			if err := dec.Decode(&rec); err != nil {
+				return nil, err
+			}
+			if rec.Name == package_ {
+				records = append(records, rec)
+			}
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+func (f condaFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	channelSubdir := f.channelSubdir
+	if !strings.Contains(channelSubdir, "://") {
+		channelSubdir = defaultChannelURL + "/" + channelSubdir
+	}
+	filename := "repodata.json"
+	if f.current {
+		filename = "current_repodata.json"
+	}
+
+	r, err := http.Get(channelSubdir + "/" + filename)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	records, err := readRecords(r.Body, f.package_)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", filename, err)
+	}
+	// old packages can have timestamp in seconds
+	for i, rec := range records {
+		if rec.Timestamp < 1e11 {
+			records[i].Timestamp = rec.Timestamp * 1000
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp > records[j].Timestamp
+	})
+
+	vs := append(filter.Versions{}, versions...)
+	for _, rec := range records {
+		var timestamp string
+		if rec.Timestamp != 0 {
+			timestamp = time.UnixMilli(rec.Timestamp).UTC().Format(time.RFC3339)
+		}
+
+		vs = append(vs, filter.NewVersionWithName(rec.Version, map[string]string{
+			"build":        rec.Build,
+			"build_number": strconv.Itoa(rec.BuildNumber),
+			"timestamp":    timestamp,
+		}))
+	}
+
+	return vs, versionKey, nil
+}
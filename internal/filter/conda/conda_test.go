@@ -0,0 +1,86 @@
+package conda_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/conda"
+)
+
+const repodata = `{
+  "info": {"subdir": "linux-64"},
+  "packages": {
+    "numpy-1.26.0-py311_0.tar.bz2": {"build": "py311_0", "build_number": 0, "depends": ["python"], "name": "numpy", "timestamp": 1695000000000, "version": "1.26.0"},
+    "scipy-1.11.0-py311_0.tar.bz2": {"build": "py311_0", "build_number": 0, "name": "scipy", "timestamp": 1696000000000, "version": "1.11.0"},
+    "numpy-1.0-py27_0.tar.bz2": {"build": "py27_0", "build_number": 0, "name": "numpy", "version": "1.0"}
+  },
+  "packages.conda": {
+    "numpy-1.26.0-py311_1.conda": {"build": "py311_1", "build_number": 1, "name": "numpy", "timestamp": 1697000000000, "version": "1.26.0"},
+    "numpy-1.25.0-py311_0.conda": {"build": "py311_0", "build_number": 0, "name": "numpy", "timestamp": 1600000000, "version": "1.25.0"},
+    "numpy-1.26.1-py311_0.conda": {"build": "py311_0", "build_number": 0, "name": "numpy", "timestamp": 1700000000, "version": "1.26.1"}
+  },
+  "repodata_version": 1
+}`
+
+const currentRepodata = `{
+  "packages.conda": {
+    "numpy-1.26.0-py311_1.conda": {"build": "py311_1", "build_number": 1, "name": "numpy", "timestamp": 1697000000000, "version": "1.26.0"}
+  }
+}`
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conda-forge/linux-64/repodata.json":
+			fmt.Fprint(w, repodata)
+		case "/conda-forge/linux-64/current_repodata.json":
+			fmt.Fprint(w, currentRepodata)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		arg      string
+		expected filter.Versions
+	}{
+		{
+			arg: ts.URL + "/conda-forge/linux-64:numpy",
+			expected: filter.Versions{
+				// timestamp in seconds sorted as milliseconds
+				{"name": "1.26.1", "build": "py311_0", "build_number": "0", "timestamp": "2023-11-14T22:13:20Z"},
+				{"name": "1.26.0", "build": "py311_1", "build_number": "1", "timestamp": "2023-10-11T04:53:20Z"},
+				{"name": "1.26.0", "build": "py311_0", "build_number": "0", "timestamp": "2023-09-18T01:20:00Z"},
+				{"name": "1.25.0", "build": "py311_0", "build_number": "0", "timestamp": "2020-09-13T12:26:40Z"},
+				{"name": "1.0", "build": "py27_0", "build_number": "0", "timestamp": ""},
+			},
+		},
+		{
+			arg: ts.URL + "/conda-forge/linux-64:numpy,current",
+			expected: filter.Versions{
+				{"name": "1.26.0", "build": "py311_1", "build_number": "1", "timestamp": "2023-10-11T04:53:20Z"},
+			},
+		},
+		{
+			arg:      ts.URL + "/conda-forge/linux-64:missing",
+			expected: filter.Versions{},
+		},
+	} {
+		t.Run(tc.arg, func(t *testing.T) {
+			f, err := conda.New(conda.Name, tc.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, _, err := f.Filter(filter.Versions{}, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tc.expected, actual)
+		})
+	}
+}
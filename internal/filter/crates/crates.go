@@ -0,0 +1,135 @@
+package crates
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://crates.io/api/v1/crates/serde
+/*
+{
+  "crate": {...},
+  "versions": [
+    {
+      "num": "1.0.193",
+      "yanked": false,
+      "license": "MIT OR Apache-2.0",
+      "created_at": "2023-11-21T01:48:38.343208+00:00",
+      ...
+    },
+    ...
+  ]
+}
+*/
+
+const defaultRegistry = "https://crates.io"
+
+// Name of filter
+const Name = "crates"
+
+// Help text
+var Help = `
+crates:<crate> or crates:<registry>:<crate>
+
+Produce versions from a crates.io compatible registry API. Name will be the
+version. Other keys are published (release time), license (SPDX expression
+like "MIT OR Apache-2.0") and yanked ("true" or "false").
+
+Versions are ordered as listed by the registry, usually newest first. Default
+registry is https://crates.io.
+
+crates:serde|^1
+# skip yanked versions
+crates:serde|@yanked|/^false$/|@name|^1
+crates:https://crates.io:tokio|*
+`[1:]
+
+// New crates filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a crate name")
+	}
+
+	registry, crate, ok := filter.SplitURLArg(arg)
+	if !ok || strings.Contains(crate, "/") {
+		return nil, fmt.Errorf("should be crates:<crate> or crates:<registry>:<crate>")
+	}
+
+	return cratesFilter{
+		registry: registry,
+		crate:    crate,
+	}, nil
+}
+
+type cratesFilter struct {
+	registry string
+	crate    string
+}
+
+func (f cratesFilter) String() string {
+	if f.registry != "" {
+		return Name + ":" + f.registry + ":" + f.crate
+	}
+	return Name + ":" + f.crate
+}
+
+func (f cratesFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	registry := f.registry
+	if registry == "" {
+		registry = defaultRegistry
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(registry, "/")+"/api/v1/crates/"+url.PathEscape(f.crate), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	// crates.io requires a user agent
+	req.Header.Add("User-Agent", "bump (https://github.com/wader/bump)")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	var response struct {
+		Versions []struct {
+			Num       string `json:"num"`
+			Yanked    bool   `json:"yanked"`
+			License   string `json:"license"`
+			CreatedAt string `json:"created_at"`
+		} `json:"versions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, "", err
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, v := range response.Versions {
+		published := v.CreatedAt
+		if t, err := time.Parse(time.RFC3339, published); err == nil {
+			published = t.UTC().Format(time.RFC3339)
+		}
+
+		vs = append(vs, filter.NewVersionWithName(v.Num, map[string]string{
+			"published": published,
+			"license":   v.License,
+			"yanked":    strconv.FormatBool(v.Yanked),
+		}))
+	}
+
+	return vs, versionKey, nil
+}
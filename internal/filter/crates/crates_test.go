@@ -0,0 +1,47 @@
+package crates_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/crates"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/crates/crate" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("User-Agent") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{
+  "crate": {"name": "crate"},
+  "versions": [
+    {"num": "1.0.1", "yanked": true, "license": "MIT OR Apache-2.0", "created_at": "2023-11-21T01:48:38.343208+00:00"},
+    {"num": "1.0.0", "yanked": false, "license": null, "created_at": "2023-11-20T00:00:00.000000+01:00"}
+  ]
+}`)
+	}))
+	defer ts.Close()
+
+	f, err := crates.New(crates.Name, ts.URL+":crate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "1.0.1", "published": "2023-11-21T01:48:38Z", "license": "MIT OR Apache-2.0", "yanked": "true"},
+		{"name": "1.0.0", "published": "2023-11-19T23:00:00Z", "license": "", "yanked": "false"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+}
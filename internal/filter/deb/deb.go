@@ -0,0 +1,262 @@
+package deb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wader/bump/internal/debversion"
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://deb.debian.org/debian/dists/bookworm/main/binary-amd64/Packages.gz | gunzip
+/*
+Package: curl
+Version: 7.88.1-10+deb12u5
+Installed-Size: 500
+Maintainer: Alessandro Ghedini <ghedo@debian.org>
+Architecture: amd64
+Depends: libc6 (>= 2.34), libcurl4 (= 7.88.1-10+deb12u5), zlib1g (>= 1:1.1.4)
+Description: command line tool for transferring data with URL syntax
+Homepage: https://curl.se/
+Description-md5: 50b3c0e8d2b3c5b8b4ab9a1d0b7c1a37
+Section: web
+Priority: optional
+Filename: pool/main/c/curl/curl_7.88.1-10+deb12u5_amd64.deb
+Size: 315468
+MD5sum: 4f4c7a9b0f7d4ae6a7a4b5e2b2c3e2e1
+SHA256: 0f1e...
+*/
+
+// Name of filter
+const Name = "deb"
+
+// Help text
+var Help = `
+deb:<mirror>:<suite>/<component>/<arch>:<package>
+
+Produce versions for a Debian or Ubuntu package from a Packages index. Name
+will be the full Debian version like "1:7.88.1-10+deb12u5". Other keys are
+upstream (version without epoch and revision), epoch, revision, source,
+filename and sha256. Versions are sorted using Debian version ordering, newest
+first.
+
+Packages.gz is tried first, then Packages.xz (requires the xz command, skipped
+if missing) and then uncompressed Packages.
+
+deb:https://deb.debian.org/debian:bookworm/main/amd64:curl
+deb:https://deb.debian.org/debian:bookworm-updates/main/amd64:tzdata|@upstream
+deb:http://archive.ubuntu.com/ubuntu:jammy/main/amd64:curl|debver:<<8
+`[1:]
+
+// New deb filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a mirror, distribution and package")
+	}
+
+	const usage = "should be deb:<mirror>:<suite>/<component>/<arch>:<package>"
+	n := strings.LastIndex(arg, ":")
+	if n == -1 || arg[n+1:] == "" {
+		return nil, fmt.Errorf(usage)
+	}
+	package_ := arg[n+1:]
+	arg = arg[0:n]
+	n = strings.LastIndex(arg, ":")
+	if n == -1 || !strings.Contains(arg[0:n], "://") {
+		return nil, fmt.Errorf(usage)
+	}
+	mirror := strings.TrimSuffix(arg[0:n], "/")
+	// suite can include slashes, ex: bookworm/updates
+	parts := strings.Split(arg[n+1:], "/")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf(usage)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf(usage)
+		}
+	}
+
+	return debFilter{
+		mirror:    mirror,
+		suite:     strings.Join(parts[0:len(parts)-2], "/"),
+		component: parts[len(parts)-2],
+		arch:      parts[len(parts)-1],
+		package_:  package_,
+	}, nil
+}
+
+type debFilter struct {
+	mirror    string
+	suite     string
+	component string
+	arch      string
+	package_  string
+}
+
+func (f debFilter) String() string {
+	return Name + ":" + f.mirror + ":" + f.suite + "/" + f.component + "/" + f.arch + ":" + f.package_
+}
+
+func gzipReader(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+
+func xzReader(r io.Reader) (io.Reader, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command("xz", "-dc")
+	cmd.Stdin = r
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("xz: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout, nil
+}
+
+func plainReader(r io.Reader) (io.Reader, error) { return r, nil }
+
+// fetchIndex fetches and decompresses an index, returns nil if not found
+func fetchIndex(u string, fn func(r io.Reader) (io.Reader, error)) ([]byte, error) {
+	r, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if r.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s: error response: %s", u, r.Status)
+	}
+
+	dr, err := fn(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+	b, err := io.ReadAll(dr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", u, err)
+	}
+
+	return b, nil
+}
+
+func (f debFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	baseURL := f.mirror + "/dists/" + f.suite + "/" + f.component + "/binary-" + f.arch + "/Packages"
+
+	var index []byte
+	xzMissing := false
+	for _, c := range []struct {
+		ext string
+		fn  func(r io.Reader) (io.Reader, error)
+	}{
+		{ext: ".gz", fn: gzipReader},
+		{ext: ".xz", fn: xzReader},
+		{ext: "", fn: plainReader},
+	} {
+		b, err := fetchIndex(baseURL+c.ext, c.fn)
+		if errors.Is(err, exec.ErrNotFound) {
+			xzMissing = true
+			continue
+		} else if err != nil {
+			return nil, "", err
+		}
+		if b != nil {
+			index = b
+			break
+		}
+	}
+	if index == nil && xzMissing {
+		return nil, "", fmt.Errorf("%s: only Packages.xz found but xz command is missing", baseURL)
+	} else if index == nil {
+		return nil, "", fmt.Errorf("%s: no Packages index found", baseURL)
+	}
+
+	type debVersion struct {
+		ver debversion.Version
+		v   filter.Version
+	}
+	var dvs []debVersion
+	addStanza := func(fields map[string]string) {
+		if fields["Package"] != f.package_ {
+			return
+		}
+		ver, err := debversion.Parse(fields["Version"])
+		if err != nil {
+			return
+		}
+		// Source can be "name (version)" when source version differs
+		source, _, _ := strings.Cut(fields["Source"], " ")
+		if source == "" {
+			source = f.package_
+		}
+		epoch := ""
+		if ver.Epoch != 0 {
+			epoch = strconv.Itoa(ver.Epoch)
+		}
+
+		dvs = append(dvs, debVersion{ver: ver, v: filter.NewVersionWithName(fields["Version"], map[string]string{
+			"upstream": ver.Upstream,
+			"epoch":    epoch,
+			"revision": ver.Revision,
+			"source":   source,
+			"filename": fields["Filename"],
+			"sha256":   fields["SHA256"],
+		})})
+	}
+
+	fields := map[string]string{}
+	lastKey := ""
+	scanner := bufio.NewScanner(bytes.NewReader(index))
+	// long Description fields
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		l := scanner.Text()
+		switch {
+		case strings.TrimSpace(l) == "":
+			addStanza(fields)
+			fields = map[string]string{}
+			lastKey = ""
+		case l[0] == ' ' || l[0] == '\t':
+			// continuation line
+			if lastKey != "" {
+				fields[lastKey] += "\n" + strings.TrimSpace(l)
+			}
+		default:
+			k, v, ok := strings.Cut(l, ":")
+			if !ok {
+				continue
+			}
+			lastKey = k
+			fields[k] = strings.TrimSpace(v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	addStanza(fields)
+
+	sort.SliceStable(dvs, func(i int, j int) bool {
+		return dvs[i].ver.Compare(dvs[j].ver) > 0
+	})
+
+	vs := append(filter.Versions{}, versions...)
+	for _, dv := range dvs {
+		vs = append(vs, dv.v)
+	}
+
+	return vs, versionKey, nil
+}
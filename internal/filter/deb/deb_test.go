@@ -0,0 +1,141 @@
+package deb_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/deb"
+)
+
+const packages = `Package: curl
+Version: 7.88.1-10+deb12u4
+Architecture: amd64
+Filename: pool/main/c/curl/curl_7.88.1-10+deb12u4_amd64.deb
+SHA256: aaa
+
+Package: libcurl4
+Source: curl (7.88.1-10+deb12u5)
+Version: 7.88.1-10+deb12u5
+Description: easy-to-use client-side URL transfer library
+ a long description
+ .
+ on multiple lines
+
+Package: curl
+Version: 7.88.1-10+deb12u5
+Architecture: amd64
+Filename: pool/main/c/curl/curl_7.88.1-10+deb12u5_amd64.deb
+SHA256: bbb
+
+Package: tzdata
+Source: tzdata (2024a-0+deb12u1)
+Version: 1:2024a-0+deb12u1
+Filename: pool/main/t/tzdata/tzdata_2024a-0+deb12u1_all.deb
+SHA256: ccc
+`
+
+func TestFilter(t *testing.T) {
+	gz := &bytes.Buffer{}
+	gw := gzip.NewWriter(gz)
+	gw.Write([]byte(packages))
+	gw.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/debian/dists/bookworm/main/binary-amd64/Packages.gz":
+			w.Write(gz.Bytes())
+		case "/debian/dists/plain/main/binary-amd64/Packages":
+			w.Write([]byte(packages))
+		case "/debian/dists/xz/main/binary-amd64/Packages.xz":
+			w.Write([]byte("not used"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	curlVersions := filter.Versions{
+		{
+			"name":     "7.88.1-10+deb12u5",
+			"upstream": "7.88.1",
+			"epoch":    "",
+			"revision": "10+deb12u5",
+			"source":   "curl",
+			"filename": "pool/main/c/curl/curl_7.88.1-10+deb12u5_amd64.deb",
+			"sha256":   "bbb",
+		},
+		{
+			"name":     "7.88.1-10+deb12u4",
+			"upstream": "7.88.1",
+			"epoch":    "",
+			"revision": "10+deb12u4",
+			"source":   "curl",
+			"filename": "pool/main/c/curl/curl_7.88.1-10+deb12u4_amd64.deb",
+			"sha256":   "aaa",
+		},
+	}
+
+	testCases := []struct {
+		arg              string
+		expectedVersions filter.Versions
+	}{
+		{arg: ts.URL + "/debian:bookworm/main/amd64:curl", expectedVersions: curlVersions},
+		{arg: ts.URL + "/debian:plain/main/amd64:curl", expectedVersions: curlVersions},
+		{
+			arg: ts.URL + "/debian:bookworm/main/amd64:tzdata",
+			expectedVersions: filter.Versions{
+				{
+					"name":     "1:2024a-0+deb12u1",
+					"upstream": "2024a",
+					"epoch":    "1",
+					"revision": "0+deb12u1",
+					"source":   "tzdata",
+					"filename": "pool/main/t/tzdata/tzdata_2024a-0+deb12u1_all.deb",
+					"sha256":   "ccc",
+				},
+			},
+		},
+		{arg: ts.URL + "/debian:bookworm/main/amd64:missing", expectedVersions: filter.Versions{}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.arg, func(t *testing.T) {
+			f, err := deb.New(deb.Name, tC.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, _, err := f.Filter(nil, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tC.expectedVersions, actual)
+		})
+	}
+
+	t.Run("missing index", func(t *testing.T) {
+		f, err := deb.New(deb.Name, ts.URL+"/debian:missing/main/amd64:curl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedErr := ts.URL + "/debian/dists/missing/main/binary-amd64/Packages: no Packages index found"
+		if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != expectedErr {
+			t.Errorf("expected error %q got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("missing xz", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		f, err := deb.New(deb.Name, ts.URL+"/debian:xz/main/amd64:curl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedErr := ts.URL + "/debian/dists/xz/main/binary-amd64/Packages: only Packages.xz found but xz command is missing"
+		if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != expectedErr {
+			t.Errorf("expected error %q got %v", expectedErr, err)
+		}
+	})
+}
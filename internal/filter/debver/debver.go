@@ -0,0 +1,135 @@
+package debver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wader/bump/internal/debversion"
+	"github.com/wader/bump/internal/filter"
+)
+
+// Name of filter
+const Name = "debver"
+
+// Help text
+var Help = `
+debver or debver:<constraint>,...
+
+Sort versions using Debian version ordering, newest first. Handles epochs
+like "1:2.0", "~" sorting before anything like "1.0~rc1" < "1.0" and
+revisions like "1.0-2". Versions that are not valid Debian versions are
+ignored.
+
+Optional comma separated constraints use dpkg relations "<<", "<=", "=", ">="
+and ">>" and all must be fulfilled.
+
+static:1.0-1,1:0.9-1,1.0~rc1-1|debver
+static:1.0-1,1.0-2,2.0~rc1-1|debver:<<2.0
+`[1:]
+
+type constraint struct {
+	op      string
+	version debversion.Version
+}
+
+var ops = []string{"<<", "<=", ">=", ">>", "="}
+
+func (c constraint) check(v debversion.Version) bool {
+	r := v.Compare(c.version)
+	switch c.op {
+	case "<<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	case "=":
+		return r == 0
+	case ">=":
+		return r >= 0
+	case ">>":
+		return r > 0
+	}
+	return false
+}
+
+// New debver filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return debverFilter{}, nil
+	}
+
+	var cs []constraint
+	for _, s := range strings.Split(arg, ",") {
+		s = strings.TrimSpace(s)
+		var c constraint
+		for _, op := range ops {
+			if rest, ok := strings.CutPrefix(s, op); ok {
+				c.op = op
+				s = rest
+				break
+			}
+		}
+		if c.op == "" {
+			return nil, fmt.Errorf("%q should start with one of %s", s, strings.Join(ops, " "))
+		}
+		c.version, err = debversion.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", s, err)
+		}
+		cs = append(cs, c)
+	}
+
+	return debverFilter{constraints: cs, constraintsStr: arg}, nil
+}
+
+type debverFilter struct {
+	constraints    []constraint
+	constraintsStr string
+}
+
+func (f debverFilter) String() string {
+	if f.constraintsStr == "" {
+		return Name
+	}
+	return Name + ":" + f.constraintsStr
+}
+
+func (f debverFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	type debVersion struct {
+		ver debversion.Version
+		v   filter.Version
+	}
+
+	var dvs []debVersion
+	for _, v := range versions {
+		ver, err := debversion.Parse(v[versionKey])
+		// ignore everything that is not a valid debian version
+		if err != nil {
+			continue
+		}
+		ok := true
+		for _, c := range f.constraints {
+			if !c.check(ver) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			dvs = append(dvs, debVersion{ver: ver, v: v})
+		}
+	}
+
+	sort.SliceStable(dvs, func(i int, j int) bool {
+		return dvs[i].ver.Compare(dvs[j].ver) > 0
+	})
+
+	var vs filter.Versions
+	for _, dv := range dvs {
+		vs = append(vs, dv.v)
+	}
+
+	return vs, versionKey, nil
+}
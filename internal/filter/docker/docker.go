@@ -15,9 +15,19 @@ var Help = `
 docker:<image>
 
 Produce versions from a image on docker hub or other registry.
-Currently only supports anonymous access.
+
+Credentials are read from $DOCKER_CONFIG/config.json or ~/.docker/config.json
+using credHelpers, auths or credsStore like docker login does. Anonymous
+access is used if no credentials are found, config can't be read or a
+credential helper is missing or fails. Config and helper errors are included in
+registry auth errors.
+
+Versions only have tag names, digests are not resolved as it would need a
+request per tag. Instead of docker:alpine|^3|@digest use dockerinfo filter
+after to get digests and image config for the selected tag.
 
 docker:alpine|^3
+docker:alpine|^3|dockerinfo:alpine,digest|@digest
 docker:mwader/static-ffmpeg|^4
 docker:ghcr.io/nginx-proxy/nginx-proxy|^0.9
 `[1:]
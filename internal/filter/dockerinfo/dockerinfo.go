@@ -0,0 +1,142 @@
+package dockerinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wader/bump/internal/dockerv2"
+	"github.com/wader/bump/internal/filter"
+)
+
+// Name of filter
+const Name = "dockerinfo"
+
+// Help text
+var Help = `
+dockerinfo:<image> or dockerinfo:<image>,<option>,...
+
+Add keys from the manifest and image config of a tag or digest in current key,
+usually after a docker filter. Keys are digest (manifest digest, for a
+multi-platform image the digest of the index), platform_digest (digest of the
+platform image, same as digest for single platform images), created (build
+time), version, revision and source from org.opencontainers.image.* labels and
+label-<name> for all labels.
+
+Only the first version is resolved by default as it will be the value of the
+pipeline. For a multi-platform image linux/amd64 or first platform is used. A
+platform without variant matches any variant, arm64 is same as arm64/v8. It is
+an error if the platform is not found.
+
+Options:
+  - all resolves all versions, does 2-3 requests per version
+  - digest only adds digest key using a HEAD request, does not count as a pull
+    on Docker Hub
+  - platform=<os/arch[/variant]> platform to use for multi-platform images
+
+docker:alpine|^3|dockerinfo:alpine|@created
+docker:alpine|^3|dockerinfo:alpine,digest|@digest
+docker:alpine|^3|dockerinfo:alpine,platform=linux/arm64|@platform_digest
+docker:ghcr.io/nginx-proxy/nginx-proxy|^1|dockerinfo:ghcr.io/nginx-proxy/nginx-proxy|@revision
+`[1:]
+
+// New dockerinfo filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a image name")
+	}
+
+	parts := strings.Split(arg, ",")
+	f := dockerinfoFilter{image: parts[0]}
+	for _, o := range parts[1:] {
+		switch {
+		case o == "all":
+			f.all = true
+		case o == "digest":
+			f.digest = true
+		case strings.HasPrefix(o, "platform="):
+			f.platform = strings.TrimPrefix(o, "platform=")
+			if _, err := dockerv2.ParsePlatform(f.platform); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown option: %s", o)
+		}
+	}
+
+	if f.digest && f.platform != "" {
+		return nil, fmt.Errorf("digest and platform options can't be combined")
+	}
+
+	f.registry, err = dockerv2.NewFromImage(f.image)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, f.image)
+	}
+
+	return f, nil
+}
+
+type dockerinfoFilter struct {
+	image    string
+	all      bool
+	digest   bool
+	platform string
+	registry *dockerv2.Registry
+}
+
+func (f dockerinfoFilter) String() string {
+	s := Name + ":" + f.image
+	if f.all {
+		s += ",all"
+	}
+	if f.digest {
+		s += ",digest"
+	}
+	if f.platform != "" {
+		s += ",platform=" + f.platform
+	}
+	return s
+}
+
+func (f dockerinfoFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	vs := append(filter.Versions{}, versions...)
+	for i, v := range vs {
+		if i > 0 && !f.all {
+			break
+		}
+
+		reference := v[versionKey]
+		if f.digest {
+			d, err := f.registry.ManifestDescriptor(reference)
+			if err != nil {
+				return nil, "", fmt.Errorf("%s: %w", reference, err)
+			}
+			nv := filter.NewVersionWithName(v["name"], v)
+			nv["digest"] = d.Digest
+			vs[i] = nv
+			continue
+		}
+
+		img, err := f.registry.ResolveImage(reference, f.platform)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", reference, err)
+		}
+		c := img.Config
+
+		nv := filter.NewVersionWithName(v["name"], v)
+		nv["digest"] = img.Digest
+		nv["platform_digest"] = img.PlatformDigest
+		nv["created"] = c.Created
+		nv["version"] = c.Config.Labels["org.opencontainers.image.version"]
+		nv["revision"] = c.Config.Labels["org.opencontainers.image.revision"]
+		nv["source"] = c.Config.Labels["org.opencontainers.image.source"]
+		for k, l := range c.Config.Labels {
+			nv["label-"+k] = l
+		}
+		vs[i] = nv
+	}
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,51 @@
+package dockerinfo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/dockerinfo"
+)
+
+func TestDigest(t *testing.T) {
+	var methods []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		if r.URL.Path != "/v2/org/image/manifests/1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+		w.Header().Set("Docker-Content-Digest", "sha256:index")
+	}))
+	defer ts.Close()
+
+	defaultClient := http.DefaultClient
+	http.DefaultClient = ts.Client()
+	defer func() { http.DefaultClient = defaultClient }()
+	// no credentials
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	f, err := dockerinfo.New(dockerinfo.Name, strings.TrimPrefix(ts.URL, "https://")+"/org/image,digest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(filter.Versions{
+		filter.NewVersionWithName("1.0", nil),
+		filter.NewVersionWithName("0.9", nil),
+	}, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deepequal.Error(t, "versions", filter.Versions{
+		{"name": "1.0", "digest": "sha256:index"},
+		{"name": "0.9"},
+	}, actual)
+	// only first version is resolved and manifest is not fetched
+	deepequal.Error(t, "requests", []string{"HEAD /v2/org/image/manifests/1.0"}, methods)
+}
@@ -0,0 +1,141 @@
+package eol
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://endoflife.date/api/nodejs.json
+/*
+[
+  {
+    "cycle": "20",
+    "releaseDate": "2023-04-18",
+    "lts": "2023-10-24",
+    "support": "2024-10-22",
+    "eol": "2026-04-30",
+    "latest": "20.10.0",
+    "latestReleaseDate": "2023-11-22"
+  },
+  {
+    "cycle": "19",
+    "releaseDate": "2022-10-18",
+    "lts": false,
+    "support": "2023-04-01",
+    "eol": "2023-06-01",
+    "latest": "19.9.0",
+    "latestReleaseDate": "2023-04-10"
+  },
+  ...
+]
+*/
+
+const defaultBaseURL = "https://endoflife.date/api"
+
+// Name of filter
+const Name = "eol"
+
+// Help text
+var Help = `
+eol:<product> or eol:<base-url>:<product>
+
+Produce versions from release cycles of a product using the endoflife.date
+API. Name will be the cycle like "20". Other keys are latest (latest release
+in the cycle), lts, eol, support and releaseDate. lts, eol and support are
+either a date like "2026-04-30", "true", "false" or empty if unknown.
+
+Cycles are ordered as in the API, usually newest first. Default base URL is
+https://endoflife.date/api.
+
+eol:nodejs|@latest
+# latest release of newest LTS cycle
+eol:nodejs|@lts|/^[t\d]/|@latest
+eol:https://endoflife.date/api:python|^3|@latest
+`[1:]
+
+// New eol filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a product")
+	}
+
+	baseURL, product, ok := filter.SplitURLArg(arg)
+	if !ok || strings.Contains(product, "/") {
+		return nil, fmt.Errorf("should be eol:<product> or eol:<base-url>:<product>")
+	}
+
+	return eolFilter{
+		baseURL: baseURL,
+		product: product,
+	}, nil
+}
+
+type eolFilter struct {
+	baseURL string
+	product string
+}
+
+func (f eolFilter) String() string {
+	if f.baseURL != "" {
+		return Name + ":" + f.baseURL + ":" + f.product
+	}
+	return Name + ":" + f.product
+}
+
+// str returns date strings as is, bools as "true" or "false" and numbers as
+// strings, used as cycle can be a number and lts, eol and support a date or bool
+func str(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func (f eolFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	baseURL := f.baseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	r, err := http.Get(strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(f.product) + ".json")
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	var cycles []map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&cycles); err != nil {
+		return nil, "", err
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, c := range cycles {
+		vs = append(vs, filter.NewVersionWithName(str(c["cycle"]), map[string]string{
+			"latest":      str(c["latest"]),
+			"lts":         str(c["lts"]),
+			"eol":         str(c["eol"]),
+			"support":     str(c["support"]),
+			"releaseDate": str(c["releaseDate"]),
+		}))
+	}
+
+	return vs, versionKey, nil
+}
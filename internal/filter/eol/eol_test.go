@@ -0,0 +1,42 @@
+package eol_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/eol"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/nodejs.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+  {"cycle": "20", "releaseDate": "2023-04-18", "lts": "2023-10-24", "support": "2024-10-22", "eol": "2026-04-30", "latest": "20.10.0"},
+  {"cycle": "19", "releaseDate": "2022-10-18", "lts": false, "support": "2023-04-01", "eol": "2023-06-01", "latest": "19.9.0"},
+  {"cycle": 3.1, "releaseDate": "2010-01-01", "lts": true, "eol": true, "latest": "3.1.2"}
+]`)
+	}))
+	defer ts.Close()
+
+	f, err := eol.New(eol.Name, ts.URL+"/api:nodejs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "20", "latest": "20.10.0", "lts": "2023-10-24", "eol": "2026-04-30", "support": "2024-10-22", "releaseDate": "2023-04-18"},
+		{"name": "19", "latest": "19.9.0", "lts": "false", "eol": "2023-06-01", "support": "2023-04-01", "releaseDate": "2022-10-18"},
+		{"name": "3.1", "latest": "3.1.2", "lts": "true", "eol": "true", "support": "", "releaseDate": "2010-01-01"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+}
@@ -0,0 +1,186 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// RSS 2.0
+/*
+<rss version="2.0">
+	<channel>
+		<item>
+			<title>/lame/3.100/lame-3.100.tar.gz</title>
+			<link>https://sourceforge.net/projects/lame/files/lame/3.100/lame-3.100.tar.gz/download</link>
+			<guid>https://sourceforge.net/projects/lame/files/lame/3.100/lame-3.100.tar.gz/download</guid>
+			<pubDate>Fri, 13 Oct 2017 18:31:42 UT</pubDate>
+		</item>
+	</channel>
+</rss>
+*/
+// Atom
+/*
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<entry>
+		<title>v1.0.0</title>
+		<link rel="alternate" href="https://example.com/releases/v1.0.0"/>
+		<id>tag:example.com,2023:1</id>
+		<updated>2023-01-01T00:00:00Z</updated>
+	</entry>
+</feed>
+*/
+// RSS 1.0 (RDF) has items outside channel and uses dc:date and rdf:about
+
+// Name of filter
+const Name = "feed"
+
+// Help text
+var Help = `
+feed:<url>
+
+Produce versions from entries in a RSS or Atom feed. Name will be the title.
+Other keys are title, link, published (RFC3339 in UTC if it could be parsed)
+and id. Versions are in feed order, usually newest first.
+
+feed:https://sourceforge.net/projects/lame/rss?path=/lame|/lame-([\d.]+)\.tar\.gz/
+feed:https://github.com/golang/go/tags.atom|/^go([\d.]+)$/|@link
+`[1:]
+
+type link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+// item is a RSS item or Atom entry, namespaces are ignored
+type item struct {
+	Title     string `xml:"title"`
+	Links     []link `xml:"link"`
+	PubDate   string `xml:"pubDate"`
+	Date      string `xml:"date"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	GUID      string `xml:"guid"`
+	ID        string `xml:"id"`
+	About     string `xml:"about,attr"`
+}
+
+type feed struct {
+	ChannelItems []item `xml:"channel>item"`
+	Items        []item `xml:"item"`
+	Entries      []item `xml:"entry"`
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC3339,
+}
+
+func firstNonEmpty(ss ...string) string {
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (i item) link() string {
+	for _, l := range i.Links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return l.Href
+		}
+	}
+	for _, l := range i.Links {
+		if s := firstNonEmpty(l.Text, l.Href); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (i item) published() string {
+	s := firstNonEmpty(i.Published, i.PubDate, i.Date, i.Updated)
+	// sourceforge uses "UT" which is not understood by time.Parse
+	ts := s
+	if strings.HasSuffix(ts, " UT") {
+		ts += "C"
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return s
+}
+
+// New feed filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a url")
+	}
+
+	return feedFilter{urlStr: arg}, nil
+}
+
+type feedFilter struct {
+	urlStr string
+}
+
+func (f feedFilter) String() string {
+	return Name + ":" + f.urlStr
+}
+
+func (f feedFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	req, err := http.NewRequest("GET", f.urlStr, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Add("User-Agent", "bump (https://github.com/wader/bump)")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	var fd feed
+	d := xml.NewDecoder(r.Body)
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	// assume charset is compatible with UTF-8 ex ISO-8859-1 with ASCII content
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := d.Decode(&fd); err != nil {
+		return nil, "", err
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, items := range [][]item{fd.ChannelItems, fd.Items, fd.Entries} {
+		for _, i := range items {
+			title := strings.TrimSpace(i.Title)
+			vs = append(vs, filter.NewVersionWithName(title, map[string]string{
+				"title":     title,
+				"link":      i.link(),
+				"published": i.published(),
+				"id":        firstNonEmpty(i.ID, i.GUID, i.About),
+			}))
+		}
+	}
+
+	return vs, versionKey, nil
+}
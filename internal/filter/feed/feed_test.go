@@ -0,0 +1,142 @@
+package feed_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/feed"
+)
+
+var feeds = map[string]string{
+	"/rss2": `<?xml version="1.0" encoding="utf-8"?>
+<rss xmlns:content="http://purl.org/rss/1.0/modules/content/" version="2.0">
+  <channel>
+    <title>LAME</title>
+    <item>
+      <title><![CDATA[/lame/3.100/lame-3.100.tar.gz]]></title>
+      <link>https://sourceforge.net/projects/lame/files/lame/3.100/lame-3.100.tar.gz/download</link>
+      <guid>https://sourceforge.net/projects/lame/files/lame/3.100/lame-3.100.tar.gz/download</guid>
+      <pubDate>Fri, 13 Oct 2017 18:31:42 UT</pubDate>
+    </item>
+    <item>
+      <title>/lame/3.99/lame-3.99.5.tar.gz</title>
+      <link>https://example.com/3.99.5</link>
+      <pubDate>Tue, 28 Feb 2012 18:50:02 +0100</pubDate>
+    </item>
+  </channel>
+</rss>
+`,
+	"/atom": `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en-US">
+  <title>Tags from go</title>
+  <link rel="self" href="https://github.com/golang/go/tags.atom"/>
+  <entry>
+    <id>tag:github.com,2008:Repository/23096959/go1.21.4</id>
+    <updated>2023-11-07T17:14:06Z</updated>
+    <link rel="alternate" type="text/html" href="https://github.com/golang/go/releases/tag/go1.21.4"/>
+    <title>go1.21.4</title>
+  </entry>
+  <entry>
+    <id>2</id>
+    <published>2023-10-10T00:00:00+02:00</published>
+    <updated>2023-11-01T00:00:00Z</updated>
+    <link href="https://example.com/2"/>
+    <title type="html">go1.21.3 &amp;amp; more</title>
+  </entry>
+</feed>
+`,
+	"/rss1": `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://savannah.gnu.org/news/"><title>News</title></channel>
+  <item rdf:about="https://savannah.gnu.org/forum/forum.php?forum_id=1">
+    <title>make 4.4.1 released</title>
+    <link>https://savannah.gnu.org/forum/forum.php?forum_id=1</link>
+    <dc:date>2023-02-26T17:44:00+00:00</dc:date>
+  </item>
+</rdf:RDF>
+`,
+}
+
+func TestFeed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := feeds[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(s))
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		path             string
+		expectedVersions filter.Versions
+	}{
+		{
+			path: "/rss2",
+			expectedVersions: filter.Versions{
+				{
+					"name":      "/lame/3.100/lame-3.100.tar.gz",
+					"title":     "/lame/3.100/lame-3.100.tar.gz",
+					"link":      "https://sourceforge.net/projects/lame/files/lame/3.100/lame-3.100.tar.gz/download",
+					"published": "2017-10-13T18:31:42Z",
+					"id":        "https://sourceforge.net/projects/lame/files/lame/3.100/lame-3.100.tar.gz/download",
+				},
+				{
+					"name":      "/lame/3.99/lame-3.99.5.tar.gz",
+					"title":     "/lame/3.99/lame-3.99.5.tar.gz",
+					"link":      "https://example.com/3.99.5",
+					"published": "2012-02-28T17:50:02Z",
+					"id":        "",
+				},
+			},
+		},
+		{
+			path: "/atom",
+			expectedVersions: filter.Versions{
+				{
+					"name":      "go1.21.4",
+					"title":     "go1.21.4",
+					"link":      "https://github.com/golang/go/releases/tag/go1.21.4",
+					"published": "2023-11-07T17:14:06Z",
+					"id":        "tag:github.com,2008:Repository/23096959/go1.21.4",
+				},
+				{
+					"name":      "go1.21.3 &amp; more",
+					"title":     "go1.21.3 &amp; more",
+					"link":      "https://example.com/2",
+					"published": "2023-10-09T22:00:00Z",
+					"id":        "2",
+				},
+			},
+		},
+		{
+			path: "/rss1",
+			expectedVersions: filter.Versions{
+				{
+					"name":      "make 4.4.1 released",
+					"title":     "make 4.4.1 released",
+					"link":      "https://savannah.gnu.org/forum/forum.php?forum_id=1",
+					"published": "2023-02-26T17:44:00Z",
+					"id":        "https://savannah.gnu.org/forum/forum.php?forum_id=1",
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.path, func(t *testing.T) {
+			f, err := feed.New(feed.Name, ts.URL+tC.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actualVersions, _, err := f.Filter(nil, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tC.expectedVersions, actualVersions)
+		})
+	}
+}
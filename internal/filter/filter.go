@@ -2,6 +2,7 @@ package filter
 
 import (
 	"errors"
+	"net/url"
 	"regexp"
 	"strings"
 )
@@ -66,6 +67,54 @@ func NewFilter(filters []NamedFilter, filterExp string) (Filter, error) {
 	return nil, ErrNoFilterMatching
 }
 
+// SplitURLArg splits "<url>:<name>" at last ":" into url and name, url is empty
+// if arg has no "://". Returns false if url is invalid or name is empty or only
+// digits as "https://host:8080" is probably a URL with a port and no name.
+func SplitURLArg(arg string) (rawURL string, name string, ok bool) {
+	if !strings.Contains(arg, "://") {
+		return "", arg, arg != ""
+	}
+	n := strings.LastIndex(arg, ":")
+	rawURL, name = arg[0:n], arg[n+1:]
+	if name == "" || strings.Trim(name, "0123456789") == "" {
+		return "", "", false
+	}
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme == "" || !strings.Contains(rawURL, "://") {
+		return "", "", false
+	}
+	return rawURL, name, true
+}
+
+// ${NAME} environment variable reference
+var envRefRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// scheme://userinfo@rest
+var userinfoRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.\-]*://)([^@/?#]*)@(.*)$`)
+
+// RedactURL returns rawURL with credentials in userinfo replaced with "xxxxx".
+// Password is redacted, username only if there is no password as it is
+// probably a token. ${ENV} references are not redacted.
+func RedactURL(rawURL string) string {
+	sm := userinfoRe.FindStringSubmatch(rawURL)
+	if sm == nil {
+		return rawURL
+	}
+	redact := func(s string) string {
+		if envRefRe.ReplaceAllString(s, "") == "" {
+			return s
+		}
+		return "xxxxx"
+	}
+	userinfo := sm[2]
+	if user, password, ok := strings.Cut(userinfo, ":"); ok {
+		userinfo = user + ":" + redact(password)
+	} else {
+		userinfo = redact(user)
+	}
+
+	return sm[1] + userinfo + "@" + sm[3]
+}
+
 // ParseHelp text
 func ParseHelp(help string) (syntax []string, description string, examples []string) {
 	syntaxSplitRe := regexp.MustCompile(`(, | or )`)
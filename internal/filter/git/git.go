@@ -14,69 +14,135 @@ const Name = "git"
 
 // Help text
 var Help = `
-git:<repo> or <repo.git>
+git:<repo>[,<tag-regexp>] or <repo.git>[,<tag-regexp>]
 
-Produce versions from tags for a git repository. Name will be
-the version found in the tag, commit the commit hash or tag object.
+Produce versions from tags for a git repository. Name will be the version
+found in the tag, by default the trailing version number after non-digits,
+otherwise the first non-empty submatch of tag-regexp or the whole match if
+it has no submatches. Tag regexp is matched against the tag name without
+"refs/tags/" and can't include "|" as it separates filters. Repo is up to the
+first "," so the tag regexp can include "," but the repo can't.
+
+Other keys are tag (tag name), commit (commit hash, peeled for annotated
+tags), tagobject (tag object hash for annotated tags, empty for lightweight
+tags) and prefix (part of tag before the version).
+
+Repo can be a http(s)://, git://, ssh://, [user@]host:path (ssh) or file://
+URL. ssh uses GIT_SSH_COMMAND or GIT_SSH if set. For http(s) credentials are
+used from URL userinfo where ${ENV} is expanded, ~/.netrc or git credential
+helpers. Credentials are redacted when shown.
 
 Use gitrefs filter to get all refs unfiltered.
 
 https://github.com/git/git.git|*
+git:git@github.com:git/git.git|*
+git:https://github.com/curl/curl.git,^curl-(\d+_\d+_\d+)$|re:/_/./|*
+https://github.com/git/git.git|^2|@commit
 `[1:]
 
-// default ref filter
-// refs/tags/<non-digits><version-number> -> version-number
-var refFilterRe = regexp.MustCompile(`^refs/tags/[^\d]*([\d\.\-]+)$`)
+// default tag filter
+// <non-digits><version-number> -> version-number
+var defaultTagRe = regexp.MustCompile(`^[^\d]*([\d\.\-]+)$`)
 
 // New git filter
 func New(prefix string, arg string) (filter filter.Filter, err error) {
+	// repo URLs rarely have "," but regexps can, ex "\d{1,3}"
+	repo, tagReStr, _ := strings.Cut(arg, ",")
+
 	// TODO hmm
 	if prefix == Name ||
-		(strings.HasSuffix(arg, ".git") &&
-			(prefix == "git" || prefix == "http" || prefix == "https")) {
-		if strings.HasPrefix(arg, "//") {
-			arg = prefix + ":" + arg
+		(strings.HasSuffix(repo, ".git") &&
+			(prefix == "git" || prefix == "http" || prefix == "https" || prefix == "ssh")) {
+		if strings.HasPrefix(repo, "//") {
+			repo = prefix + ":" + repo
 		}
 	} else {
 		return nil, nil
 	}
 
-	if arg == "" {
+	if repo == "" {
 		return nil, fmt.Errorf("needs a repo")
 	}
 
-	return gitFilter{repo: arg}, nil
+	f := gitFilter{repo: repo, tagRe: defaultTagRe}
+	if tagReStr != "" {
+		f.tagRe, err = regexp.Compile(tagReStr)
+		if err != nil {
+			return nil, err
+		}
+		f.tagReStr = tagReStr
+	}
+
+	return f, nil
 }
 
 type gitFilter struct {
-	repo string
+	repo     string
+	tagRe    *regexp.Regexp
+	tagReStr string
 }
 
 func (f gitFilter) String() string {
-	return Name + ":" + f.repo
+	if f.tagReStr != "" {
+		return Name + ":" + filter.RedactURL(f.repo) + "," + f.tagReStr
+	}
+	return Name + ":" + filter.RedactURL(f.repo)
+}
+
+// version returns version and prefix for tag, version is first non-empty
+// submatch or whole match if no submatches
+func (f gitFilter) version(tag string) (string, string, bool) {
+	sm := f.tagRe.FindStringSubmatchIndex(tag)
+	if sm == nil {
+		return "", "", false
+	}
+	if len(sm) == 2 {
+		return tag[sm[0]:sm[1]], tag[0:sm[0]], true
+	}
+	for i := 2; i < len(sm); i += 2 {
+		if sm[i] != -1 && sm[i] != sm[i+1] {
+			return tag[sm[i]:sm[i+1]], tag[0:sm[i]], true
+		}
+	}
+	return "", "", false
 }
 
 func (f gitFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
-	refPairs, err := gitrefs.Refs(f.repo, gitrefs.AllProtos)
+	refPairs, err := gitrefs.RefsWithPrefixes(f.repo, []string{"refs/tags/"}, gitrefs.AllProtos)
 	if err != nil {
 		return nil, "", err
 	}
 
+	// annotated tags has a peeled "<tag>^{}" ref with the commit
+	peeled := map[string]string{}
+	for _, p := range refPairs {
+		if name, ok := strings.CutSuffix(p.Name, "^{}"); ok {
+			peeled[name] = p.ObjID
+		}
+	}
+
 	vs := append(filter.Versions{}, versions...)
 	for _, p := range refPairs {
-		sm := refFilterRe.FindStringSubmatch(p.Name)
-		// find first non-empty submatch
-		if sm == nil {
+		tag, ok := strings.CutPrefix(p.Name, "refs/tags/")
+		if !ok || strings.HasSuffix(tag, "^{}") {
 			continue
 		}
-		var name string
-		for _, m := range sm[1:] {
-			if m != "" {
-				name = m
-				break
-			}
+		name, tagPrefix, ok := f.version(tag)
+		if !ok {
+			continue
 		}
-		vs = append(vs, filter.NewVersionWithName(name, map[string]string{"commit": p.ObjID}))
+
+		commit, tagObject := p.ObjID, ""
+		if c, ok := peeled[p.Name]; ok {
+			commit, tagObject = c, p.ObjID
+		}
+
+		vs = append(vs, filter.NewVersionWithName(name, map[string]string{
+			"tag":       tag,
+			"commit":    commit,
+			"tagobject": tagObject,
+			"prefix":    tagPrefix,
+		}))
 	}
 
 	return vs, versionKey, nil
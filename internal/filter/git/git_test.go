@@ -0,0 +1,80 @@
+package git_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/git"
+)
+
+// served using dumb HTTP protocol
+const infoRefs = `
+1111111111111111111111111111111111111111	refs/heads/main
+2222222222222222222222222222222222222222	refs/tags/v1.0.0
+3333333333333333333333333333333333333333	refs/tags/v1.1.0
+4444444444444444444444444444444444444444	refs/tags/v1.1.0^{}
+5555555555555555555555555555555555555555	refs/tags/1.2.3-20240101.1
+6666666666666666666666666666666666666666	refs/tags/release-2024.05
+7777777777777777777777777777777777777777	refs/tags/curl-8_7_1
+`
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repo.git/info/refs" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, infoRefs[1:])
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		arg      string
+		expected filter.Versions
+	}{
+		{
+			arg: ts.URL + "/repo.git",
+			expected: filter.Versions{
+				{"name": "1.0.0", "tag": "v1.0.0", "commit": "2222222222222222222222222222222222222222", "tagobject": "", "prefix": "v"},
+				{"name": "1.1.0", "tag": "v1.1.0", "commit": "4444444444444444444444444444444444444444", "tagobject": "3333333333333333333333333333333333333333", "prefix": "v"},
+				{"name": "1.2.3-20240101.1", "tag": "1.2.3-20240101.1", "commit": "5555555555555555555555555555555555555555", "tagobject": "", "prefix": ""},
+				{"name": "2024.05", "tag": "release-2024.05", "commit": "6666666666666666666666666666666666666666", "tagobject": "", "prefix": "release-"},
+			},
+		},
+		{
+			arg: ts.URL + `/repo.git,^curl-(\d+_\d+_\d+)$`,
+			expected: filter.Versions{
+				{"name": "8_7_1", "tag": "curl-8_7_1", "commit": "7777777777777777777777777777777777777777", "tagobject": "", "prefix": "curl-"},
+			},
+		},
+		{
+			arg: ts.URL + `/repo.git,^curl-(\d{1,3}_\d{1,3}_\d{1,3})$`,
+			expected: filter.Versions{
+				{"name": "8_7_1", "tag": "curl-8_7_1", "commit": "7777777777777777777777777777777777777777", "tagobject": "", "prefix": "curl-"},
+			},
+		},
+		{
+			arg: ts.URL + `/repo.git,^v1\.1\.0$`,
+			expected: filter.Versions{
+				{"name": "v1.1.0", "tag": "v1.1.0", "commit": "4444444444444444444444444444444444444444", "tagobject": "3333333333333333333333333333333333333333", "prefix": ""},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.arg, func(t *testing.T) {
+			f, err := git.New(git.Name, tc.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, _, err := f.Filter(nil, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tc.expected, actual)
+		})
+	}
+}
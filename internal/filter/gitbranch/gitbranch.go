@@ -0,0 +1,121 @@
+package gitbranch
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/gitrefs"
+)
+
+// Name of filter
+const Name = "gitbranch"
+
+// Help text
+var Help = `
+gitbranch:<repo>:<branch>
+
+Produce a version for the head commit of a branch in a git repository. Name
+will be the commit hash. Other keys are branch and ref (like
+"refs/heads/main").
+
+Repo can be a http(s)://, git://, ssh://, [user@]host:path (ssh) or file://
+URL. Branch is after the last ":".
+
+If repo is a local clone (file://) date (committer date in UTC) and subject keys are
+also added.
+
+gitbranch:https://github.com/git/git.git:master
+gitbranch:git@github.com:git/git.git:master
+gitbranch:file:///src/repo:main|@subject
+`[1:]
+
+// New gitbranch filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a repo and branch")
+	}
+
+	// branch names can't include ":"
+	n := strings.LastIndex(arg, ":")
+	if n == -1 || arg[n+1:] == "" {
+		return nil, fmt.Errorf("should be gitbranch:<repo>:<branch>")
+	}
+	// make sure repo is a URL with a path so that a port is not taken as branch,
+	// ex: https://host:8443/repo.git
+	if u, err := gitrefs.ParseURL(arg[0:n]); err != nil || u.Scheme == "" || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("should be gitbranch:<repo>:<branch>")
+	}
+
+	return gitBranchFilter{
+		repo:   arg[0:n],
+		branch: arg[n+1:],
+	}, nil
+}
+
+type gitBranchFilter struct {
+	repo   string
+	branch string
+}
+
+func (f gitBranchFilter) String() string {
+	return Name + ":" + filter.RedactURL(f.repo) + ":" + f.branch
+}
+
+// commitInfo returns committer date and subject for a commit in a local repo
+func commitInfo(dir string, commit string) (string, string, error) {
+	c := exec.Command("git", "log", "-1", "--format=%cI%n%s", commit)
+	c.Dir = dir
+	b, err := c.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("git log: %w", err)
+	}
+	date, subject, _ := strings.Cut(strings.TrimSpace(string(b)), "\n")
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		date = t.UTC().Format(time.RFC3339)
+	}
+	return date, subject, nil
+}
+
+func (f gitBranchFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	ref := "refs/heads/" + f.branch
+	refPairs, err := gitrefs.RefsWithPrefixes(f.repo, []string{ref}, gitrefs.AllProtos)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var commit string
+	for _, p := range refPairs {
+		if p.Name == ref {
+			commit = p.ObjID
+			break
+		}
+	}
+	if commit == "" {
+		return nil, "", fmt.Errorf("branch %q not found", f.branch)
+	}
+
+	values := map[string]string{
+		"branch": f.branch,
+		"ref":    ref,
+	}
+	if u, err := url.Parse(f.repo); err == nil && u.Scheme == "file" {
+		date, subject, err := commitInfo(u.Path, commit)
+		if err != nil {
+			return nil, "", err
+		}
+		values["date"] = date
+		values["subject"] = subject
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	vs = append(vs, filter.NewVersionWithName(commit, values))
+
+	return vs, versionKey, nil
+}
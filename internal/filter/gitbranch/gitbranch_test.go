@@ -0,0 +1,74 @@
+package gitbranch_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/gitbranch"
+)
+
+func TestLocalRepo(t *testing.T) {
+	tempDir := t.TempDir()
+
+	runOrFatal := func(arg ...string) string {
+		c := exec.Command(arg[0], arg[1:]...)
+		c.Dir = tempDir
+		c.Env = append(c.Environ(), "GIT_COMMITTER_DATE=2024-05-01T12:00:00+02:00")
+		b, err := c.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	runOrFatal("git", "init", "-b", "main", ".")
+	runOrFatal("git", "config", "user.email", "test")
+	runOrFatal("git", "config", "user.name", "test")
+	runOrFatal("git", "commit", "--allow-empty", "--author", "test <test@test>", "--message", "first")
+	runOrFatal("git", "branch", "feature/a")
+	runOrFatal("git", "commit", "--allow-empty", "--author", "test <test@test>", "--message", "second")
+	mainSha := strings.TrimSpace(runOrFatal("git", "rev-parse", "main"))
+	featureSha := strings.TrimSpace(runOrFatal("git", "rev-parse", "feature/a"))
+
+	for _, tc := range []struct {
+		branch   string
+		expected filter.Versions
+	}{
+		{
+			branch: "main",
+			expected: filter.Versions{
+				{"name": mainSha, "branch": "main", "ref": "refs/heads/main", "date": "2024-05-01T10:00:00Z", "subject": "second"},
+			},
+		},
+		{
+			branch: "feature/a",
+			expected: filter.Versions{
+				{"name": featureSha, "branch": "feature/a", "ref": "refs/heads/feature/a", "date": "2024-05-01T10:00:00Z", "subject": "first"},
+			},
+		},
+	} {
+		t.Run(tc.branch, func(t *testing.T) {
+			f, err := gitbranch.New(gitbranch.Name, "file://"+tempDir+":"+tc.branch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, _, err := f.Filter(nil, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tc.expected, actual)
+		})
+	}
+
+	f, err := gitbranch.New(gitbranch.Name, "file://"+tempDir+":missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = f.Filter(nil, "name")
+	if err == nil || err.Error() != `branch "missing" not found` {
+		t.Errorf("expected not found error got %v", err)
+	}
+}
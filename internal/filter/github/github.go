@@ -0,0 +1,111 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/github"
+)
+
+// Name of filter
+const Name = "github"
+
+// Help text
+var Help = `
+github:<owner>/<repo>
+
+Produce versions from releases for a GitHub repository. Name will be the
+release tag name. Other keys are title, prerelease, draft, published_at, url,
+commitish and assets (space separated asset names).
+
+GITHUB_TOKEN will be used if set to avoid rate limiting and GITHUB_API_URL can
+be used to change API base URL, for example to a GitHub enterprise server.
+
+At most 1000 newest releases are fetched.
+
+github:cli/cli|*
+# skip prereleases
+github:cli/cli|@prerelease|/^false$/|@name|^2
+# link to release notes
+github:cli/cli|^2|@url
+`[1:]
+
+// max number of release pages to fetch, same as gitlab filter
+const maxPages = 10
+
+const perPage = 100
+
+// New github releases filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a repo")
+	}
+
+	parts := strings.Split(arg, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("should be github:<owner>/<repo>")
+	}
+
+	return githubFilter{repo: arg}, nil
+}
+
+type githubFilter struct {
+	repo string
+}
+
+func (f githubFilter) String() string {
+	return Name + ":" + f.repo
+}
+
+func (f githubFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	c := &github.Client{
+		BaseURL: os.Getenv("GITHUB_API_URL"),
+		Token:   os.Getenv("GITHUB_TOKEN"),
+	}
+	repo := c.NewRepoRef(f.repo)
+
+	vs := append(filter.Versions{}, versions...)
+	for page := 1; page <= maxPages; page++ {
+		releases, err := repo.ListReleases(
+			"per_page", strconv.Itoa(perPage),
+			"page", strconv.Itoa(page),
+		)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, r := range releases {
+			var assetNames []string
+			for _, a := range r.Assets {
+				assetNames = append(assetNames, a.Name)
+			}
+			var publishedAt string
+			if !r.PublishedAt.IsZero() {
+				publishedAt = r.PublishedAt.Format(time.RFC3339)
+			}
+
+			vs = append(vs, filter.NewVersionWithName(r.TagName, map[string]string{
+				"title":        r.Name,
+				"prerelease":   strconv.FormatBool(r.Prerelease),
+				"draft":        strconv.FormatBool(r.Draft),
+				"published_at": publishedAt,
+				"url":          r.HTMLURL,
+				"commitish":    r.TargetCommitish,
+				"assets":       strings.Join(assetNames, " "),
+			}))
+		}
+
+		if len(releases) < perPage {
+			break
+		}
+	}
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,145 @@
+package github_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/github"
+)
+
+func TestFilter(t *testing.T) {
+	const releaseCount = 150
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "token abc" {
+			t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+		}
+
+		count := releaseCount
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases":
+		case "/repos/owner/many/releases":
+			// always full pages
+			count = 10000
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var releases []map[string]any
+		for i := (page - 1) * perPage; i < page*perPage && i < count; i++ {
+			releases = append(releases, map[string]any{
+				"tag_name":         fmt.Sprintf("v1.%d", count-i),
+				"name":             fmt.Sprintf("Release %d", count-i),
+				"prerelease":       i == 0,
+				"draft":            i == 1,
+				"published_at":     "2023-01-01T00:00:00Z",
+				"html_url":         fmt.Sprintf("https://github.com/owner/repo/releases/v1.%d", count-i),
+				"target_commitish": "main",
+				"assets":           []map[string]any{{"name": "a.tar.gz"}, {"name": "b.zip"}},
+			})
+		}
+		json.NewEncoder(w).Encode(releases)
+	}))
+	defer ts.Close()
+
+	t.Setenv("GITHUB_API_URL", ts.URL+"/")
+	t.Setenv("GITHUB_TOKEN", "abc")
+
+	f, err := github.New(github.Name, "owner/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests got %d", requests)
+	}
+	if len(actual) != releaseCount {
+		t.Fatalf("expected %d versions got %d", releaseCount, len(actual))
+	}
+	expected := filter.Versions{
+		{
+			"name":         "v1.150",
+			"title":        "Release 150",
+			"prerelease":   "true",
+			"draft":        "false",
+			"published_at": "2023-01-01T00:00:00Z",
+			"url":          "https://github.com/owner/repo/releases/v1.150",
+			"commitish":    "main",
+			"assets":       "a.tar.gz b.zip",
+		},
+		{
+			"name":         "v1.149",
+			"title":        "Release 149",
+			"prerelease":   "false",
+			"draft":        "true",
+			"published_at": "2023-01-01T00:00:00Z",
+			"url":          "https://github.com/owner/repo/releases/v1.149",
+			"commitish":    "main",
+			"assets":       "a.tar.gz b.zip",
+		},
+	}
+	deepequal.Error(t, "versions", expected, actual[0:2])
+	if actual[releaseCount-1]["name"] != "v1.1" {
+		t.Errorf("expected last version v1.1 got %s", actual[releaseCount-1]["name"])
+	}
+
+	// stops after max pages with newest releases
+	requests = 0
+	f, err = github.New(github.Name, "owner/many")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err = f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 10 || len(actual) != 1000 || actual[0]["name"] != "v1.10000" {
+		t.Errorf("expected 10 requests and 1000 newest versions got %d %d %s", requests, len(actual), actual[0]["name"])
+	}
+
+	f, err = github.New(github.Name, "owner/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != "404 Not Found" {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
+
+func TestEnterpriseBaseURL(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		fmt.Fprint(w, `[{"tag_name":"v1.0"}]`)
+	}))
+	defer ts.Close()
+
+	// GitHub enterprise server actions has base URL without trailing slash
+	t.Setenv("GITHUB_API_URL", ts.URL+"/api/v3")
+
+	f, err := github.New(github.Name, "owner/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 1 || actual[0]["name"] != "v1.0" {
+		t.Errorf("unexpected versions %v", actual)
+	}
+	deepequal.Error(t, "paths", []string{"/api/v3/repos/owner/repo/releases"}, paths)
+}
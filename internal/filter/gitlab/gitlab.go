@@ -0,0 +1,173 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// Name of filter
+const Name = "gitlab"
+
+// Help text
+var Help = `
+gitlab:<host>/<group>/<project>
+
+Produce versions from tags and releases for a GitLab project using the REST
+API. Name will be the tag name. Other keys are commit, message, release (release
+name), released_at, upcoming_release and url. Release keys are empty if the tag
+has no release.
+
+Host can be prefixed with http:// or https:// (default) for self-hosted
+instances. GITLAB_TOKEN will be used if set to access private projects.
+
+At most 1000 newest tags and releases are fetched.
+
+gitlab:gitlab.com/gitlab-org/gitlab-runner|^16
+gitlab:gitlab.com/gitlab-org/gitlab-runner|^16|@commit
+`[1:]
+
+// max number of pages to fetch per list, same as github filter
+const maxPages = 10
+
+const perPage = 100
+
+// New gitlab filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a project")
+	}
+
+	scheme := "https"
+	hostProject := arg
+	if s, rest, ok := strings.Cut(arg, "://"); ok {
+		if s != "http" && s != "https" {
+			return nil, fmt.Errorf("unsupported scheme %q", s)
+		}
+		scheme = s
+		hostProject = rest
+	}
+
+	host, project, _ := strings.Cut(hostProject, "/")
+	if host == "" || !strings.Contains(project, "/") {
+		return nil, fmt.Errorf("should be gitlab:<host>/<group>/<project>")
+	}
+
+	return gitlabFilter{
+		arg:     arg,
+		baseURL: scheme + "://" + host + "/api/v4/projects/" + url.PathEscape(project),
+	}, nil
+}
+
+type gitlabFilter struct {
+	arg     string
+	baseURL string
+}
+
+func (f gitlabFilter) String() string {
+	return Name + ":" + f.arg
+}
+
+// getPaged gets pages by following X-Next-Page headers, stops after maxPages
+func getPaged[T any](rawURL string, token string) ([]T, error) {
+	var vs []T
+
+	page := "1"
+	for i := 0; page != "" && i < maxPages; i++ {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?per_page=%d&page=%s", rawURL, perPage, url.QueryEscape(page)), nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+
+		r, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.StatusCode/100 != 2 {
+			r.Body.Close()
+			return nil, fmt.Errorf("error response: %s", r.Status)
+		}
+
+		var pageVs []T
+		err = json.NewDecoder(r.Body).Decode(&pageVs)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, pageVs...)
+
+		page = r.Header.Get("X-Next-Page")
+	}
+
+	return vs, nil
+}
+
+type tag struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Commit  struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+type release struct {
+	TagName         string `json:"tag_name"`
+	Name            string `json:"name"`
+	ReleasedAt      string `json:"released_at"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+	Links           struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+func (f gitlabFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	token := os.Getenv("GITLAB_TOKEN")
+
+	tags, err := getPaged[tag](f.baseURL+"/repository/tags", token)
+	if err != nil {
+		return nil, "", err
+	}
+	releases, err := getPaged[release](f.baseURL+"/releases", token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tagReleases := map[string]release{}
+	for _, r := range releases {
+		tagReleases[r.TagName] = r
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, t := range tags {
+		values := map[string]string{
+			"commit":           t.Commit.ID,
+			"message":          t.Message,
+			"release":          "",
+			"released_at":      "",
+			"upcoming_release": "",
+			"url":              "",
+		}
+		if r, ok := tagReleases[t.Name]; ok {
+			values["release"] = r.Name
+			values["released_at"] = r.ReleasedAt
+			values["upcoming_release"] = strconv.FormatBool(r.UpcomingRelease)
+			values["url"] = r.Links.Self
+		}
+		vs = append(vs, filter.NewVersionWithName(t.Name, values))
+	}
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,105 @@
+package gitlab_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/gitlab"
+)
+
+func TestFilter(t *testing.T) {
+	requests := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "abc" {
+			t.Errorf("unexpected PRIVATE-TOKEN header %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("unexpected per_page %q", r.URL.Query().Get("per_page"))
+		}
+		requests[r.URL.Path]++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fproject/repository/tags":
+			// two pages
+			if page == 1 {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprint(w, `[{"name":"v2.0.0","message":"","commit":{"id":"bbb"}}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"name":"v1.0.0","message":"first","commit":{"id":"aaa"}}]`)
+		case "/api/v4/projects/group%2Fproject/releases":
+			fmt.Fprint(w, `[{"tag_name":"v2.0.0","name":"Release 2","released_at":"2023-01-01T00:00:00Z","upcoming_release":false,"_links":{"self":"https://gitlab.example.com/group/project/-/releases/v2.0.0"}}]`)
+		case "/api/v4/projects/group%2Fmany/repository/tags":
+			// always a next page
+			w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+			json.NewEncoder(w).Encode([]map[string]any{{"name": fmt.Sprintf("v%d", page)}})
+		case "/api/v4/projects/group%2Fmany/releases":
+			fmt.Fprint(w, `[]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	t.Setenv("GITLAB_TOKEN", "abc")
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	f, err := gitlab.New(gitlab.Name, "http://"+host+"/group/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{
+			"name":             "v2.0.0",
+			"commit":           "bbb",
+			"message":          "",
+			"release":          "Release 2",
+			"released_at":      "2023-01-01T00:00:00Z",
+			"upcoming_release": "false",
+			"url":              "https://gitlab.example.com/group/project/-/releases/v2.0.0",
+		},
+		{
+			"name":             "v1.0.0",
+			"commit":           "aaa",
+			"message":          "first",
+			"release":          "",
+			"released_at":      "",
+			"upcoming_release": "",
+			"url":              "",
+		},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	// stops after max pages
+	f, err = gitlab.New(gitlab.Name, "http://"+host+"/group/many")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err = f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := requests["/api/v4/projects/group/many/repository/tags"]; n != 10 || len(actual) != 10 || actual[0]["name"] != "v1" {
+		t.Errorf("expected 10 requests and versions got %d %d", n, len(actual))
+	}
+
+	f, err = gitlab.New(gitlab.Name, "http://"+host+"/group/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != "error response: 404 Not Found" {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
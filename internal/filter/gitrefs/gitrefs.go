@@ -17,6 +17,8 @@ gitrefs:<repo>
 Produce versions from all refs for a git repository. Name will be the whole ref
 like "refs/tags/v2.7.3" and commit will be the commit hash.
 
+Repo URLs and credentials work the same as for git filter.
+
 Use git filter to get versions from only tags.
 
 gitrefs:https://github.com/git/git.git
@@ -40,7 +42,7 @@ type gitRefsFilter struct {
 }
 
 func (f gitRefsFilter) String() string {
-	return Name + ":" + f.repo
+	return Name + ":" + filter.RedactURL(f.repo)
 }
 
 func (f gitRefsFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
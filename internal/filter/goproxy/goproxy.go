@@ -0,0 +1,262 @@
+package goproxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// https://go.dev/ref/mod#goproxy-protocol
+
+const defaultProxy = "https://proxy.golang.org"
+
+// Name of filter
+const Name = "goproxy"
+
+// Help text
+var Help = `
+goproxy:<module>[,info] or goproxy:<proxy>:<module>[,info]
+
+Produce versions for a go module using the GOPROXY protocol. Name will be the
+version without leading "v". Other keys are version (with leading "v") and
+incompatible (true for +incompatible versions).
+
+With info option keys time, origin (VCS URL), origin_ref and origin_hash are
+also added if provided by the proxy. Info does one request per version.
+
+Default proxy is first http(s) or file URL in GOPROXY or https://proxy.golang.org.
+If a module has no tagged versions its latest pseudo-version is used.
+
+goproxy:golang.org/x/net|*
+goproxy:github.com/Masterminds/semver/v3|^3
+goproxy:https://proxy.golang.org:golang.org/x/text,info|^0|@time
+`[1:]
+
+var errNotFound = errors.New("not found")
+
+// concurrent info requests
+const infoWorkers = 8
+
+// New goproxy filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a module path")
+	}
+
+	var withInfo bool
+	if a, o, ok := strings.Cut(arg, ","); ok {
+		if o != "info" {
+			return nil, fmt.Errorf("unknown option: %s", o)
+		}
+		arg, withInfo = a, true
+	}
+
+	proxy, module, ok := filter.SplitURLArg(arg)
+	if !ok || strings.HasPrefix(module, "/") {
+		return nil, fmt.Errorf("should be goproxy:<module> or goproxy:<proxy>:<module>")
+	}
+
+	return goproxyFilter{
+		proxy:    proxy,
+		module:   module,
+		withInfo: withInfo,
+	}, nil
+}
+
+type goproxyFilter struct {
+	proxy    string
+	module   string
+	withInfo bool
+}
+
+func (f goproxyFilter) String() string {
+	s := Name + ":" + f.module
+	if f.proxy != "" {
+		s = Name + ":" + f.proxy + ":" + f.module
+	}
+	if f.withInfo {
+		s += ",info"
+	}
+	return s
+}
+
+// escapePath escapes upper case letters as "!" followed by lower case letter
+// as module paths and versions are case sensitive but file systems might not be
+func escapePath(s string) string {
+	sb := &strings.Builder{}
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			sb.WriteByte('!')
+			sb.WriteRune(r + ('a' - 'A'))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// proxyFromEnv returns first usable proxy URL from a GOPROXY value
+// entries are separated by "," or "|" and can be "direct" or "off"
+func proxyFromEnv(goproxy string) string {
+	for _, p := range strings.FieldsFunc(goproxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "https://") ||
+			strings.HasPrefix(p, "http://") ||
+			strings.HasPrefix(p, "file://") {
+			return p
+		}
+	}
+	return ""
+}
+
+func get(proxy string, path string) ([]byte, error) {
+	u, err := url.Parse(strings.TrimSuffix(proxy, "/") + "/" + path)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "file" {
+		b, err := os.ReadFile(filepath.FromSlash(u.Path))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", path, errNotFound)
+		}
+		return b, err
+	}
+
+	r, err := http.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode == http.StatusNotFound || r.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("%s: %w", path, errNotFound)
+	}
+	if r.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("error response: %s", r.Status)
+	}
+
+	return io.ReadAll(r.Body)
+}
+
+type info struct {
+	Version string `json:"Version"`
+	Time    string `json:"Time"`
+	Origin  struct {
+		VCS  string `json:"VCS"`
+		URL  string `json:"URL"`
+		Ref  string `json:"Ref"`
+		Hash string `json:"Hash"`
+	} `json:"Origin"`
+}
+
+func getInfo(proxy string, path string) (info, error) {
+	var i info
+	b, err := get(proxy, path)
+	if err != nil {
+		return i, err
+	}
+	if err := json.Unmarshal(b, &i); err != nil {
+		return i, err
+	}
+	return i, nil
+}
+
+// getInfos fills in infos for versions using concurrent requests
+func getInfos(proxy string, modulePath string, infos []info) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(infos))
+	indexCh := make(chan int)
+	for i := 0; i < infoWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexCh {
+				infos[i], errs[i] = getInfo(proxy, modulePath+"/@v/"+escapePath(infos[i].Version)+".info")
+			}
+		}()
+	}
+	for i := range infos {
+		indexCh <- i
+	}
+	close(indexCh)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f goproxyFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	proxy := f.proxy
+	if proxy == "" {
+		proxy = proxyFromEnv(os.Getenv("GOPROXY"))
+	}
+	if proxy == "" {
+		proxy = defaultProxy
+	}
+
+	modulePath := escapePath(f.module)
+
+	b, err := get(proxy, modulePath+"/@v/list")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var infos []info
+	for _, l := range strings.Split(string(b), "\n") {
+		v := strings.TrimSpace(l)
+		if v == "" {
+			continue
+		}
+		infos = append(infos, info{Version: v})
+	}
+	if f.withInfo {
+		if err := getInfos(proxy, modulePath, infos); err != nil {
+			return nil, "", err
+		}
+	}
+	// no tagged versions, use latest pseudo-version
+	if len(infos) == 0 {
+		i, err := getInfo(proxy, modulePath+"/@latest")
+		if err != nil && !errors.Is(err, errNotFound) {
+			return nil, "", err
+		} else if err == nil {
+			infos = append(infos, i)
+		}
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, i := range infos {
+		values := map[string]string{
+			"version":      i.Version,
+			"incompatible": strconv.FormatBool(strings.HasSuffix(i.Version, "+incompatible")),
+		}
+		if f.withInfo {
+			values["time"] = i.Time
+			values["origin"] = i.Origin.URL
+			values["origin_ref"] = i.Origin.Ref
+			values["origin_hash"] = i.Origin.Hash
+		}
+		vs = append(vs, filter.NewVersionWithName(strings.TrimPrefix(i.Version, "v"), values))
+	}
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,103 @@
+package goproxy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/goproxy"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileProxy(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"example.com/!user/mod/@v/list": "v1.0.0\nv2.0.0+incompatible\n",
+		"example.com/!user/mod/@v/v1.0.0.info": `
+{"Version":"v1.0.0","Time":"2020-01-01T00:00:00Z","Origin":{"VCS":"git","URL":"https://example.com/User/mod","Ref":"refs/tags/v1.0.0","Hash":"aaa"}}
+`,
+		"example.com/!user/mod/@v/v2.0.0+incompatible.info": `
+{"Version":"v2.0.0+incompatible","Time":"2021-01-01T00:00:00Z"}
+`,
+		"example.com/!user/mod/v3/@v/list": "",
+		"example.com/!user/mod/v3/@latest": `
+{"Version":"v3.0.0-20220101000000-bbbbbbbbbbbb","Time":"2022-01-01T00:00:00Z"}
+`,
+	})
+
+	testCases := []struct {
+		module           string
+		expectedVersions filter.Versions
+	}{
+		{
+			module: "example.com/User/mod",
+			expectedVersions: filter.Versions{
+				{"name": "1.0.0", "version": "v1.0.0", "incompatible": "false"},
+				{"name": "2.0.0+incompatible", "version": "v2.0.0+incompatible", "incompatible": "true"},
+			},
+		},
+		{
+			module: "example.com/User/mod,info",
+			expectedVersions: filter.Versions{
+				{
+					"name":         "1.0.0",
+					"version":      "v1.0.0",
+					"time":         "2020-01-01T00:00:00Z",
+					"incompatible": "false",
+					"origin":       "https://example.com/User/mod",
+					"origin_ref":   "refs/tags/v1.0.0",
+					"origin_hash":  "aaa",
+				},
+				{
+					"name":         "2.0.0+incompatible",
+					"version":      "v2.0.0+incompatible",
+					"time":         "2021-01-01T00:00:00Z",
+					"incompatible": "true",
+					"origin":       "",
+					"origin_ref":   "",
+					"origin_hash":  "",
+				},
+			},
+		},
+		{
+			module: "example.com/User/mod/v3,info",
+			expectedVersions: filter.Versions{
+				{
+					"name":         "3.0.0-20220101000000-bbbbbbbbbbbb",
+					"version":      "v3.0.0-20220101000000-bbbbbbbbbbbb",
+					"time":         "2022-01-01T00:00:00Z",
+					"incompatible": "false",
+					"origin":       "",
+					"origin_ref":   "",
+					"origin_hash":  "",
+				},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.module, func(t *testing.T) {
+			f, err := goproxy.New(goproxy.Name, "file://"+filepath.ToSlash(tempDir)+":"+tC.module)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actualVersions, _, err := f.Filter(nil, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tC.expectedVersions, actualVersions)
+		})
+	}
+}
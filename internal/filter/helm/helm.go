@@ -0,0 +1,147 @@
+package helm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/yaml"
+)
+
+// curl https://charts.bitnami.com/bitnami/index.yaml
+/*
+apiVersion: v1
+entries:
+  nginx:
+  - apiVersion: v2
+    appVersion: 1.25.3
+    created: "2023-11-01T10:00:00.123456789Z"
+    deprecated: false
+    digest: 5f1c...
+    name: nginx
+    urls:
+    - https://charts.bitnami.com/bitnami/nginx-15.4.0.tgz
+    version: 15.4.0
+  ...
+generated: "2023-11-01T10:00:00.123456789Z"
+*/
+
+// Name of filter
+const Name = "helm"
+
+// Help text
+var Help = `
+helm:<repo>:<chart>
+
+Produce versions from a helm chart repository index.yaml. Name will be the
+chart version. Other keys are appVersion, created, digest, deprecated ("true"
+or "false") and url (first chart archive URL).
+
+helm:https://charts.bitnami.com/bitnami:nginx|^15
+helm:https://charts.bitnami.com/bitnami:nginx|^15|@appVersion
+`[1:]
+
+// New helm filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a repo and chart")
+	}
+
+	n := strings.LastIndex(arg, ":")
+	if n == -1 || !strings.Contains(arg[0:n], "://") || arg[n+1:] == "" {
+		return nil, fmt.Errorf("should be helm:<repo>:<chart>")
+	}
+	repo := arg[0:n]
+	if _, err := url.Parse(repo); err != nil {
+		return nil, err
+	}
+
+	return helmFilter{
+		repo:  repo,
+		chart: arg[n+1:],
+	}, nil
+}
+
+type helmFilter struct {
+	repo  string
+	chart string
+}
+
+func (f helmFilter) String() string {
+	return Name + ":" + f.repo + ":" + f.chart
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func (f helmFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	repoURL, err := url.Parse(strings.TrimSuffix(f.repo, "/") + "/")
+	if err != nil {
+		return nil, "", err
+	}
+	indexURL := repoURL.ResolveReference(&url.URL{Path: "index.yaml"})
+
+	r, err := http.Get(indexURL.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	index, err := yaml.Unmarshal(b)
+	if err != nil {
+		return nil, "", fmt.Errorf("index.yaml: %w", err)
+	}
+
+	indexMap, _ := index.(map[string]any)
+	entries, _ := indexMap["entries"].(map[string]any)
+	chartVersions, ok := entries[f.chart].([]any)
+	if !ok {
+		return nil, "", fmt.Errorf("chart %q not found", f.chart)
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, cv := range chartVersions {
+		m, ok := cv.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var chartURL string
+		if urls, ok := m["urls"].([]any); ok && len(urls) > 0 {
+			if u, err := url.Parse(str(urls[0])); err == nil {
+				// urls can be relative to repo url
+				chartURL = repoURL.ResolveReference(u).String()
+			}
+		}
+		deprecated := "false"
+		if str(m["deprecated"]) == "true" {
+			deprecated = "true"
+		}
+
+		vs = append(vs, filter.NewVersionWithName(str(m["version"]), map[string]string{
+			"appVersion": str(m["appVersion"]),
+			"created":    str(m["created"]),
+			"digest":     str(m["digest"]),
+			"deprecated": deprecated,
+			"url":        chartURL,
+		}))
+	}
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,105 @@
+package helm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/helm"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/charts/index.yaml":
+			fmt.Fprint(w, `
+apiVersion: v1
+entries:
+  app:
+  - apiVersion: v2
+    appVersion: "2.0"
+    created: "2023-02-01T00:00:00Z"
+    digest: bbb
+    name: app
+    urls:
+    - app-2.0.0.tgz
+    version: 2.0.0
+  - appVersion: 1.0
+    created: "2023-01-01T00:00:00Z"
+    deprecated: true
+    digest: aaa
+    name: app
+    urls:
+    - https://cdn.example.com/app-1.0.0.tgz
+    version: 1.0.0
+generated: "2023-02-01T00:00:00Z"
+`)
+		case "/anchors/index.yaml":
+			fmt.Fprint(w, `
+apiVersion: v1
+entries:
+  app:
+  - &app
+    version: 1.0.0
+  - *app
+`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	chartVersions := filter.Versions{
+		{
+			"name":       "2.0.0",
+			"appVersion": "2.0",
+			"created":    "2023-02-01T00:00:00Z",
+			"digest":     "bbb",
+			"deprecated": "false",
+			"url":        ts.URL + "/charts/app-2.0.0.tgz",
+		},
+		{
+			"name":       "1.0.0",
+			"appVersion": "1.0",
+			"created":    "2023-01-01T00:00:00Z",
+			"digest":     "aaa",
+			"deprecated": "true",
+			"url":        "https://cdn.example.com/app-1.0.0.tgz",
+		},
+	}
+
+	testCases := []struct {
+		arg              string
+		expectedVersions filter.Versions
+		expectedErr      string
+	}{
+		{arg: ts.URL + "/charts:app", expectedVersions: chartVersions},
+		// relative urls are resolved against repo with or without trailing slash
+		{arg: ts.URL + "/charts/:app", expectedVersions: chartVersions},
+		{arg: ts.URL + "/charts:missing", expectedErr: `chart "missing" not found`},
+		{arg: ts.URL + "/anchors:app", expectedErr: "index.yaml: line 5: anchors, aliases and tags are not supported"},
+		{arg: ts.URL + "/missing:app", expectedErr: "error response: 404 Not Found"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.arg, func(t *testing.T) {
+			f, err := helm.New(helm.Name, tC.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actualVersions, _, err := f.Filter(nil, "name")
+			if tC.expectedErr != "" {
+				if err == nil || err.Error() != tC.expectedErr {
+					t.Fatalf("expected error %q got %v", tC.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tC.expectedVersions, actualVersions)
+		})
+	}
+}
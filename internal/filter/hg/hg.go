@@ -0,0 +1,113 @@
+package hg
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// hgweb raw style tags, newest first
+// curl 'https://hg.nginx.org/nginx?cmd=tags&style=raw'
+/*
+tip	<node>
+release-1.25.3	<node>
+release-1.25.2	<node>
+...
+*/
+
+// Name of filter
+const Name = "hg"
+
+// Help text
+var Help = `
+hg:<repo>
+
+Produce versions from tags for a mercurial repository using hgweb (like hg
+serve). Name will be the version found in the tag like for the git filter.
+Other keys are tag and node (changeset hash).
+
+hg:https://hg.nginx.org/nginx|*
+hg:https://hg.nginx.org/nginx|^1.25|@node
+`[1:]
+
+// <non-digits><version-number> -> version-number
+var tagRe = regexp.MustCompile(`^[^\d]*([\d\.\-]+)$`)
+
+// New hg filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a repo")
+	}
+	u, err := url.Parse(arg)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("repo should be a http or https URL")
+	}
+
+	return hgFilter{repo: arg}, nil
+}
+
+type hgFilter struct {
+	repo string
+}
+
+func (f hgFilter) String() string {
+	return Name + ":" + f.repo
+}
+
+func (f hgFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	u, err := url.Parse(f.repo)
+	if err != nil {
+		return nil, "", err
+	}
+	q := u.Query()
+	q.Set("cmd", "tags")
+	q.Set("style", "raw")
+	u.RawQuery = q.Encode()
+
+	r, err := http.Get(u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		tag, node, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			return nil, "", fmt.Errorf("unexpected tags line: %s", scanner.Text())
+		}
+		if tag == "tip" {
+			continue
+		}
+		sm := tagRe.FindStringSubmatch(tag)
+		if sm == nil {
+			continue
+		}
+
+		vs = append(vs, filter.NewVersionWithName(sm[1], map[string]string{
+			"tag":  tag,
+			"node": node,
+		}))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+
+	return vs, versionKey, nil
+}
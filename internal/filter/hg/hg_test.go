@@ -0,0 +1,51 @@
+package hg_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/hg"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repo" || r.URL.Query().Get("cmd") != "tags" || r.URL.Query().Get("style") != "raw" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, ""+
+			"tip\t3333333333333333333333333333333333333333\n"+
+			"release-1.25.3\t2222222222222222222222222222222222222222\n"+
+			"other\t1111111111111111111111111111111111111111\n"+
+			"v1.0.0\t0000000000000000000000000000000000000000\n",
+		)
+	}))
+	defer ts.Close()
+
+	f, err := hg.New(hg.Name, ts.URL+"/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "1.25.3", "tag": "release-1.25.3", "node": "2222222222222222222222222222222222222222"},
+		{"name": "1.0.0", "tag": "v1.0.0", "node": "0000000000000000000000000000000000000000"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	f, err = hg.New(hg.Name, ts.URL+"/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = f.Filter(nil, "name")
+	if err == nil || err.Error() != "error response: 404 Not Found" {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
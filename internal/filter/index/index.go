@@ -0,0 +1,220 @@
+package index
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// Apache table autoindex
+/*
+<tr><td valign="top"><img src="/icons/compressed.gif" alt="[   ]"></td><td><a href="ffmpeg-6.1.tar.xz">ffmpeg-6.1.tar.xz</a></td><td align="right">2023-11-10 23:12  </td><td align="right"> 10M</td><td>&nbsp;</td></tr>
+*/
+// Apache pre autoindex
+/*
+<img src="/icons/compressed.gif" alt="[   ]"> <a href="make-4.4.tar.gz">make-4.4.tar.gz</a>         2022-10-31 06:20  2.2M
+*/
+// nginx autoindex
+/*
+<a href="linux-6.6.tar.xz">linux-6.6.tar.xz</a>                                   30-Oct-2023 02:32    136M
+*/
+
+// Name of filter
+const Name = "index"
+
+// Help text
+var Help = `
+index:<url>
+
+Produce versions from links in a HTML directory listing like Apache or nginx
+autoindex pages. Name will be the last path segment of the link without
+trailing slash. Other keys are href (absolute URL), size (as shown in the
+listing, empty for directories) and mtime (RFC3339 in UTC if it could be parsed).
+
+Links to sort options, parent or other directories are ignored. Versions are in
+listing order.
+
+index:https://ffmpeg.org/releases/|/^ffmpeg-([\d.]+)\.tar\.xz$/
+index:https://ftp.gnu.org/gnu/make/|/^make-([\d.]+)\.tar\.gz$/|^4
+`[1:]
+
+// New index filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a url")
+	}
+	u, err := url.Parse(arg)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("should be index:<url> with a http or https url")
+	}
+
+	return indexFilter{urlStr: arg}, nil
+}
+
+type indexFilter struct {
+	urlStr string
+}
+
+func (f indexFilter) String() string {
+	return Name + ":" + f.urlStr
+}
+
+var anchorRe = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))[^>]*>`)
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+var rowEndRe = regexp.MustCompile(`(?i)</tr>|\n`)
+
+// 2023-11-10 23:12, 10-Nov-2023 23:12, 2023-Nov-10 23:12:01 (lighttpd)
+var mtimeRe = regexp.MustCompile(`(\d{4}-\d{2}-\d{2}|\d{2}-[A-Za-z]{3}-\d{4}|\d{4}-[A-Za-z]{3}-\d{2})[ T](\d{2}:\d{2}(?::\d{2})?)\s*(\S*)`)
+var mtimeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"02-Jan-2006 15:04",
+	"02-Jan-2006 15:04:05",
+	"2006-Jan-02 15:04",
+	"2006-Jan-02 15:04:05",
+}
+
+type entry struct {
+	name  string
+	href  string
+	size  string
+	mtime string
+}
+
+// parseListing finds links that are files or directories inside base and
+// mtime and size after the link on the same line or table row
+func parseListing(base *url.URL, s string) []*entry {
+	var entries []*entry
+	seen := map[string]*entry{}
+
+	basePath := base.Path
+	if !strings.HasSuffix(basePath, "/") {
+		basePath = path.Dir(basePath) + "/"
+	}
+
+	ms := anchorRe.FindAllStringSubmatchIndex(s, -1)
+	for i, m := range ms {
+		var href string
+		for j := 2; j < 8; j += 2 {
+			if m[j] != -1 {
+				href = html.UnescapeString(s[m[j]:m[j+1]])
+				break
+			}
+		}
+		if href == "" || strings.HasPrefix(href, "?") || strings.HasPrefix(href, "#") {
+			continue
+		}
+		hu, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		hu = base.ResolveReference(hu)
+		hu.Fragment = ""
+		if hu.Host != base.Host || hu.RawQuery != "" {
+			continue
+		}
+		rel, ok := strings.CutPrefix(hu.Path, basePath)
+		// only direct children, ignores parent directory and other absolute links
+		if !ok || rel == "" || strings.Contains(strings.TrimSuffix(rel, "/"), "/") {
+			continue
+		}
+
+		// text after link until next link, end of table row or end of line
+		rest := s[m[1]:]
+		if i+1 < len(ms) {
+			rest = s[m[1]:ms[i+1][0]]
+		}
+		if n := strings.Index(strings.ToLower(rest), "</a>"); n != -1 {
+			rest = rest[n+4:]
+		}
+		if l := rowEndRe.FindStringIndex(rest); l != nil {
+			rest = rest[0:l[0]]
+		}
+		text := html.UnescapeString(tagRe.ReplaceAllString(rest, " "))
+
+		var mtime, size string
+		if tm := mtimeRe.FindStringSubmatch(text); tm != nil {
+			mtime = tm[1] + " " + tm[2]
+			for _, layout := range mtimeLayouts {
+				if t, err := time.Parse(layout, mtime); err == nil {
+					mtime = t.UTC().Format(time.RFC3339)
+					break
+				}
+			}
+			size = strings.TrimSpace(tm[3])
+			if size == "-" {
+				size = ""
+			}
+		}
+
+		hrefStr := hu.String()
+		if e, ok := seen[hrefStr]; ok {
+			// icon and name can be separate links to the same entry
+			if e.mtime == "" {
+				e.mtime = mtime
+			}
+			if e.size == "" {
+				e.size = size
+			}
+			continue
+		}
+		e := &entry{
+			name:  strings.TrimSuffix(rel, "/"),
+			href:  hrefStr,
+			size:  size,
+			mtime: mtime,
+		}
+		seen[hrefStr] = e
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+func (f indexFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	req, err := http.NewRequest("GET", f.urlStr, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Add("User-Agent", "bump (https://github.com/wader/bump)")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// use final URL after redirects to resolve relative links
+	vs := append(filter.Versions{}, versions...)
+	for _, e := range parseListing(r.Request.URL, string(b)) {
+		vs = append(vs, filter.NewVersionWithName(e.name, map[string]string{
+			"href":  e.href,
+			"size":  e.size,
+			"mtime": e.mtime,
+		}))
+	}
+
+	return vs, versionKey, nil
+}
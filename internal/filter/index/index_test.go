@@ -0,0 +1,97 @@
+package index_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/index"
+)
+
+var listings = map[string]string{
+	"/apache-table/": `<html><body><h1>Index of /apache-table</h1>
+<table>
+<tr><th valign="top"><img src="/icons/blank.gif" alt="[ICO]"></th><th><a href="?C=N;O=D">Name</a></th><th><a href="?C=M;O=A">Last modified</a></th><th><a href="?C=S;O=A">Size</a></th></tr>
+<tr><td valign="top"><img src="/icons/back.gif" alt="[PARENTDIR]"></td><td><a href="/">Parent Directory</a></td><td>&nbsp;</td><td align="right">  - </td></tr>
+<tr><td valign="top"><img src="/icons/folder.gif" alt="[DIR]"></td><td><a href="docs/">docs/</a></td><td align="right">2023-11-09 10:00  </td><td align="right">  - </td></tr>
+<tr><td valign="top"><img src="/icons/compressed.gif" alt="[   ]"></td><td><a href="ffmpeg-6.1.tar.xz">ffmpeg-6.1.tar.xz</a></td><td align="right">2023-11-10 23:12  </td><td align="right"> 10M</td><td>&nbsp;</td></tr>
+<tr><td valign="top"><img src="/icons/compressed.gif" alt="[   ]"></td><td><a href="ffmpeg-6.1.tar.xz.asc">ffmpeg-6.1.tar.xz.asc</a></td><td align="right">2023-11-10 23:12  </td><td align="right">520 </td><td>&nbsp;</td></tr>
+</table>
+<address>Apache Server at example.com Port 80</address>
+</body></html>
+`,
+	"/apache-pre/": `<html><body><h1>Index of /apache-pre</h1>
+<pre><img src="/icons/blank.gif" alt="Icon "> <a href="?C=N;O=D">Name</a>                    <a href="?C=M;O=A">Last modified</a>      <a href="?C=S;O=A">Size</a>  <hr><a href="/"><img src="/icons/back.gif" alt="[PARENTDIR]"></a> <a href="/">Parent Directory</a>                             -
+<a href="make-4.4.tar.gz"><img src="/icons/compressed.gif" alt="[   ]"></a> <a href="make-4.4.tar.gz">make-4.4.tar.gz</a>         2022-10-31 06:20  2.2M
+<a href="make-4.4.1.tar.gz"><img src="/icons/compressed.gif" alt="[   ]"></a> <a href="make-4.4.1.tar.gz">make-4.4.1.tar.gz</a>       2023-02-26 17:44  2.2M
+<hr></pre>
+</body></html>
+`,
+	"/nginx/": `<html>
+<head><title>Index of /nginx/</title></head>
+<body>
+<h1>Index of /nginx/</h1><hr><pre><a href="../">../</a>
+<a href="v6.x/">v6.x/</a>                                              30-Oct-2023 02:32                   -
+<a href="linux-6.6.tar.xz">linux-6.6.tar.xz</a>                                   30-Oct-2023 02:32           141073924
+<a href="a%20b-1.0.tar.gz">a b-1.0.tar.gz</a>                                     01-Jan-2023 00:00:01             1
+<a href="https://other.example.com/x-1.0.tar.gz">x-1.0.tar.gz</a>
+</pre><hr></body>
+</html>
+`,
+}
+
+func TestIndex(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := listings[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(s))
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		path             string
+		expectedVersions filter.Versions
+	}{
+		{
+			path: "/apache-table/",
+			expectedVersions: filter.Versions{
+				{"name": "docs", "href": ts.URL + "/apache-table/docs/", "mtime": "2023-11-09T10:00:00Z", "size": ""},
+				{"name": "ffmpeg-6.1.tar.xz", "href": ts.URL + "/apache-table/ffmpeg-6.1.tar.xz", "mtime": "2023-11-10T23:12:00Z", "size": "10M"},
+				{"name": "ffmpeg-6.1.tar.xz.asc", "href": ts.URL + "/apache-table/ffmpeg-6.1.tar.xz.asc", "mtime": "2023-11-10T23:12:00Z", "size": "520"},
+			},
+		},
+		{
+			path: "/apache-pre/",
+			expectedVersions: filter.Versions{
+				{"name": "make-4.4.tar.gz", "href": ts.URL + "/apache-pre/make-4.4.tar.gz", "mtime": "2022-10-31T06:20:00Z", "size": "2.2M"},
+				{"name": "make-4.4.1.tar.gz", "href": ts.URL + "/apache-pre/make-4.4.1.tar.gz", "mtime": "2023-02-26T17:44:00Z", "size": "2.2M"},
+			},
+		},
+		{
+			path: "/nginx/",
+			expectedVersions: filter.Versions{
+				{"name": "v6.x", "href": ts.URL + "/nginx/v6.x/", "mtime": "2023-10-30T02:32:00Z", "size": ""},
+				{"name": "linux-6.6.tar.xz", "href": ts.URL + "/nginx/linux-6.6.tar.xz", "mtime": "2023-10-30T02:32:00Z", "size": "141073924"},
+				{"name": "a b-1.0.tar.gz", "href": ts.URL + "/nginx/a%20b-1.0.tar.gz", "mtime": "2023-01-01T00:00:01Z", "size": "1"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.path, func(t *testing.T) {
+			f, err := index.New(index.Name, ts.URL+tC.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actualVersions, _, err := f.Filter(nil, "name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "versions", tC.expectedVersions, actualVersions)
+		})
+	}
+}
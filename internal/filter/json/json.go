@@ -0,0 +1,79 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/jsonpath"
+)
+
+// Name of filter
+const Name = "json"
+
+// Help text
+var Help = `
+json:<path>
+
+Parse value of current key as JSON and produce one version per element
+selected by path. Objects fields will be keys. Nested objects and arrays will
+be compact JSON so they can be used with another json filter. A "name" field
+will be the name otherwise name will be the element, strings unquoted and
+other values as JSON.
+
+Path syntax is a subset of JSONPath:
+  - .field or ["field"] selects an object field
+  - [n] selects an array element, negative n counts from the end
+  - [*] or .* selects all array elements or object values (sorted by key)
+  - empty path or $ selects the whole value
+
+fetch:https://nodejs.org/dist/index.json|json:[*]|@version
+fetch:https://go.dev/dl/?mode=json|json:[*]|@version|/^go(.*)$/
+fetch:https://api.releases.hashicorp.com/v1/releases/terraform|json:[*]|@version
+`[1:]
+
+// New json filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	path, err := jsonpath.Parse(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonFilter{pathStr: arg, path: path}, nil
+}
+
+type jsonFilter struct {
+	pathStr string
+	path    jsonpath.Path
+}
+
+func (f jsonFilter) String() string {
+	return Name + ":" + f.pathStr
+}
+
+func (f jsonFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	var vs filter.Versions
+	for _, v := range versions {
+		d := json.NewDecoder(strings.NewReader(v[versionKey]))
+		d.UseNumber()
+		var root any
+		if err := d.Decode(&root); err != nil {
+			return nil, "", fmt.Errorf("json: %w", err)
+		}
+
+		for _, sv := range f.path.Select(root) {
+			fields := jsonpath.Fields(sv)
+			name, ok := fields["name"]
+			if !ok {
+				name = jsonpath.String(sv)
+			}
+			vs = append(vs, filter.NewVersionWithName(name, fields))
+		}
+	}
+
+	return vs, "name", nil
+}
@@ -0,0 +1,100 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/json"
+)
+
+func TestFilter(t *testing.T) {
+	const nodeIndex = `[
+  {"version":"v21.2.0","date":"2023-11-14","files":["linux-x64","osx-arm64-tar"],"lts":false,"security":false},
+  {"version":"v20.9.0","date":"2023-10-24","files":["linux-x64"],"lts":"Iron","security":false,"modules":"115"}
+]`
+
+	testCases := []struct {
+		path             string
+		input            string
+		expectedVersions filter.Versions
+		expectedErr      string
+	}{
+		{
+			path:  "[*]",
+			input: nodeIndex,
+			expectedVersions: filter.Versions{
+				{
+					"name":     `{"date":"2023-11-14","files":["linux-x64","osx-arm64-tar"],"lts":false,"security":false,"version":"v21.2.0"}`,
+					"version":  "v21.2.0",
+					"date":     "2023-11-14",
+					"files":    `["linux-x64","osx-arm64-tar"]`,
+					"lts":      "false",
+					"security": "false",
+				},
+				{
+					"name":     `{"date":"2023-10-24","files":["linux-x64"],"lts":"Iron","modules":"115","security":false,"version":"v20.9.0"}`,
+					"version":  "v20.9.0",
+					"date":     "2023-10-24",
+					"files":    `["linux-x64"]`,
+					"lts":      "Iron",
+					"modules":  "115",
+					"security": "false",
+				},
+			},
+		},
+		{
+			path:             "$[-1].files[0]",
+			input:            nodeIndex,
+			expectedVersions: filter.Versions{{"name": "linux-x64"}},
+		},
+		{
+			path:  `.versions.*`,
+			input: `{"name":"terraform","versions":{"1.6.1":{"name":"terraform","version":"1.6.1"},"1.6.0":{"name":"terraform","version":"1.6.0"}}}`,
+			expectedVersions: filter.Versions{
+				{"name": "terraform", "version": "1.6.0"},
+				{"name": "terraform", "version": "1.6.1"},
+			},
+		},
+		{
+			path:             `["a b"][*]`,
+			input:            `{"a b":[1.50,"<s>",null,true]}`,
+			expectedVersions: filter.Versions{{"name": "1.50"}, {"name": "<s>"}, {"name": ""}, {"name": "true"}},
+		},
+		{
+			path:             "",
+			input:            `"a"`,
+			expectedVersions: filter.Versions{{"name": "a"}},
+		},
+		{
+			path:             ".missing[*]",
+			input:            `{}`,
+			expectedVersions: nil,
+		},
+		{
+			path:        "[*]",
+			input:       `[`,
+			expectedErr: "json: unexpected EOF",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.path, func(t *testing.T) {
+			f, err := json.New(json.Name, tC.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actualVersions, actualKey, err := f.Filter(filter.Versions{{"name": tC.input}}, "name")
+			if tC.expectedErr != "" {
+				if err == nil || err.Error() != tC.expectedErr {
+					t.Fatalf("expected error %q got %v", tC.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "key", "name", actualKey)
+			deepequal.Error(t, "versions", tC.expectedVersions, actualVersions)
+		})
+	}
+}
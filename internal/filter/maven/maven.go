@@ -0,0 +1,142 @@
+package maven
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://repo.maven.apache.org/maven2/log4j/log4j/maven-metadata.xml
+/*
+<metadata>
+	<groupId>log4j</groupId>
+	<artifactId>log4j</artifactId>
+	<versioning>
+		<latest>1.2.17</latest>
+		<release>1.2.17</release>
+		<versions>
+			<version>1.1.3</version>
+			...
+			<version>1.2.17</version>
+		</versions>
+		<lastUpdated>20140318154402</lastUpdated>
+	</versioning>
+</metadata>
+*/
+
+const defaultRepo = "https://repo.maven.apache.org/maven2"
+
+// Name of filter
+const Name = "maven"
+
+// Help text
+var Help = `
+maven:<groupId>:<artifactId> or maven:<repo>:<groupId>:<artifactId>
+
+Produce versions from maven-metadata.xml in a Maven layout repository like
+Maven Central, Nexus or Artifactory. Name will be the version. Other keys are
+release and latest ("true" if the version is the release or latest version)
+and lastUpdated for the metadata.
+
+Versions are ordered as in the metadata but reversed, usually newest first.
+Default repository is https://repo.maven.apache.org/maven2. Credentials can be
+provided as user info in the repository URL and are redacted when shown.
+
+maven:log4j:log4j|^1
+maven:https://repo.maven.apache.org/maven2:org.apache.commons:commons-lang3|^3
+maven:org.apache.commons:commons-lang3|@release|/^true$/|@name
+`[1:]
+
+type metadata struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Versioning struct {
+		Latest      string   `xml:"latest"`
+		Release     string   `xml:"release"`
+		Versions    []string `xml:"versions>version"`
+		LastUpdated string   `xml:"lastUpdated"`
+	} `xml:"versioning"`
+}
+
+// New maven filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a groupId and artifactId")
+	}
+
+	parts := strings.Split(arg, ":")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("should be maven:<groupId>:<artifactId> or maven:<repo>:<groupId>:<artifactId>")
+	}
+	groupID := parts[len(parts)-2]
+	artifactID := parts[len(parts)-1]
+	repo := strings.Join(parts[0:len(parts)-2], ":")
+	if groupID == "" || artifactID == "" || (repo != "" && !strings.Contains(repo, "://")) {
+		return nil, fmt.Errorf("should be maven:<groupId>:<artifactId> or maven:<repo>:<groupId>:<artifactId>")
+	}
+
+	return mavenFilter{
+		repo:       repo,
+		groupID:    groupID,
+		artifactID: artifactID,
+	}, nil
+}
+
+type mavenFilter struct {
+	repo       string
+	groupID    string
+	artifactID string
+}
+
+func (f mavenFilter) String() string {
+	if f.repo != "" {
+		return Name + ":" + filter.RedactURL(f.repo) + ":" + f.groupID + ":" + f.artifactID
+	}
+	return Name + ":" + f.groupID + ":" + f.artifactID
+}
+
+func (f mavenFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	repo := f.repo
+	if repo == "" {
+		repo = defaultRepo
+	}
+
+	// org.apache.commons:commons-lang3 -> org/apache/commons/commons-lang3/maven-metadata.xml
+	r, err := http.Get(
+		strings.TrimSuffix(repo, "/") + "/" +
+			strings.ReplaceAll(f.groupID, ".", "/") + "/" +
+			f.artifactID + "/maven-metadata.xml",
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	var m metadata
+	if err := xml.NewDecoder(r.Body).Decode(&m); err != nil {
+		return nil, "", err
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for i := len(m.Versioning.Versions) - 1; i >= 0; i-- {
+		v := strings.TrimSpace(m.Versioning.Versions[i])
+		vs = append(vs, filter.NewVersionWithName(v, map[string]string{
+			"release":     strconv.FormatBool(v == m.Versioning.Release),
+			"latest":      strconv.FormatBool(v == m.Versioning.Latest),
+			"lastUpdated": m.Versioning.LastUpdated,
+		}))
+	}
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,81 @@
+package maven_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/maven"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/maven2/org/example/app/maven-metadata.xml":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>org.example</groupId>
+  <artifactId>app</artifactId>
+  <versioning>
+    <latest>2.0.0-SNAPSHOT</latest>
+    <release>1.1.0</release>
+    <versions>
+      <version>1.0.0</version>
+      <version> 1.1.0 </version>
+      <version>2.0.0-SNAPSHOT</version>
+    </versions>
+    <lastUpdated>20240101120000</lastUpdated>
+  </versioning>
+</metadata>
+`)
+		case "/maven2/org/example/broken/maven-metadata.xml":
+			fmt.Fprint(w, `<metadata><versioning>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := strings.Replace(ts.URL, "http://", "http://user:secret@", 1) + "/maven2"
+
+	f, err := maven.New(maven.Name, repo+":org.example:app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedString := "maven:" + strings.Replace(ts.URL, "http://", "http://user:xxxxx@", 1) + "/maven2:org.example:app"
+	if f.String() != expectedString {
+		t.Errorf("expected %q got %q", expectedString, f.String())
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "2.0.0-SNAPSHOT", "release": "false", "latest": "true", "lastUpdated": "20240101120000"},
+		{"name": "1.1.0", "release": "true", "latest": "false", "lastUpdated": "20240101120000"},
+		{"name": "1.0.0", "release": "false", "latest": "false", "lastUpdated": "20240101120000"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	for arg, expectedErr := range map[string]string{
+		repo + ":org.example:broken":       "XML syntax error on line 1: unexpected EOF",
+		repo + ":org.example:missing":      "error response: 404 Not Found",
+		ts.URL + "/maven2:org.example:app": "error response: 401 Unauthorized",
+	} {
+		f, err := maven.New(maven.Name, arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := f.Filter(nil, "name"); err == nil || err.Error() != expectedErr {
+			t.Errorf("%s: expected error %q got %v", arg, expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+const defaultRegistry = "https://registry.npmjs.org"
+
+// Name of filter
+const Name = "npm"
+
+// Help text
+var Help = `
+npm:<package> or npm:<registry>:<package>
+
+Produce versions from a npm registry. Name will be the version. Other keys are
+time (publish time), deprecated (deprecation message if deprecated), dist-tags
+(space separated dist-tags pointing to the version) and dist-tag-<tag> with
+the version each dist-tag points to, set on all versions so that
+@dist-tag-<tag> works no matter which version is first.
+
+Versions are ordered by publish time, newest first.
+
+Default registry is https://registry.npmjs.org or npm_config_registry if set.
+Registry URL is up to the last ":" and package name can't be only digits.
+
+npm:react|*
+npm:react|@dist-tag-latest
+npm:@types/node|^20
+# skip deprecated versions
+npm:request|@deprecated|/^$/|@name|*
+`[1:]
+
+// New npm filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a package name")
+	}
+
+	registry, package_, ok := filter.SplitURLArg(arg)
+	if !ok || (strings.Contains(package_, "/") && !strings.HasPrefix(package_, "@")) {
+		return nil, fmt.Errorf("should be npm:<package> or npm:<registry>:<package>")
+	}
+
+	return npmFilter{
+		registry: registry,
+		package_: package_,
+	}, nil
+}
+
+type npmFilter struct {
+	registry string
+	package_ string
+}
+
+func (f npmFilter) String() string {
+	if f.registry != "" {
+		return Name + ":" + f.registry + ":" + f.package_
+	}
+	return Name + ":" + f.package_
+}
+
+func (f npmFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	var response struct {
+		DistTags map[string]string `json:"dist-tags"`
+		Versions map[string]struct {
+			Deprecated any `json:"deprecated"`
+		} `json:"versions"`
+		Time map[string]string `json:"time"`
+	}
+
+	registry := f.registry
+	if registry == "" {
+		registry = os.Getenv("npm_config_registry")
+	}
+	if registry == "" {
+		registry = defaultRegistry
+	}
+
+	// scoped packages are escaped as @scope%2fname
+	r, err := http.Get(strings.TrimSuffix(registry, "/") + "/" + strings.Replace(f.package_, "/", "%2f", 1))
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	jd := json.NewDecoder(r.Body)
+	if err := jd.Decode(&response); err != nil {
+		return nil, "", err
+	}
+
+	versionDistTags := map[string][]string{}
+	for t, v := range response.DistTags {
+		versionDistTags[v] = append(versionDistTags[v], t)
+	}
+
+	var npmVs filter.Versions
+	for v, vi := range response.Versions {
+		var deprecated string
+		// deprecated is usually a message string but can also be a boolean
+		switch d := vi.Deprecated.(type) {
+		case string:
+			deprecated = d
+		case bool:
+			if d {
+				deprecated = "true"
+			}
+		}
+
+		distTags := versionDistTags[v]
+		sort.Strings(distTags)
+
+		values := map[string]string{
+			"time":       response.Time[v],
+			"deprecated": deprecated,
+			"dist-tags":  strings.Join(distTags, " "),
+		}
+		for t, tv := range response.DistTags {
+			values["dist-tag-"+t] = tv
+		}
+
+		npmVs = append(npmVs, filter.NewVersionWithName(v, values))
+	}
+	// time is RFC 3339 in UTC so can be compared as strings
+	sort.Slice(npmVs, func(i, j int) bool {
+		if npmVs[i]["time"] == npmVs[j]["time"] {
+			return npmVs[i]["name"] > npmVs[j]["name"]
+		}
+		return npmVs[i]["time"] > npmVs[j]["time"]
+	})
+
+	vs := append(filter.Versions{}, versions...)
+	vs = append(vs, npmVs...)
+
+	return vs, versionKey, nil
+}
@@ -0,0 +1,84 @@
+package npm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/npm"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/@scope%2fpackage" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{
+	"dist-tags": {"latest": "1.1.0", "stable": "1.1.0", "next": "2.0.0-rc.1"},
+	"versions": {
+		"1.0.0": {"deprecated": "use 1.1.0"},
+		"1.1.0": {},
+		"2.0.0-rc.1": {"deprecated": true}
+	},
+	"time": {
+		"created": "2023-01-01T00:00:00.000Z",
+		"modified": "2023-03-01T00:00:00.000Z",
+		"1.0.0": "2023-01-01T00:00:00.000Z",
+		"1.1.0": "2023-02-01T00:00:00.000Z",
+		"2.0.0-rc.1": "2023-03-01T00:00:00.000Z"
+	}
+}`)
+	}))
+	defer ts.Close()
+
+	f, err := npm.New(npm.Name, ts.URL+":@scope/package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{
+			"name":            "2.0.0-rc.1",
+			"time":            "2023-03-01T00:00:00.000Z",
+			"deprecated":      "true",
+			"dist-tags":       "next",
+			"dist-tag-latest": "1.1.0",
+			"dist-tag-stable": "1.1.0",
+			"dist-tag-next":   "2.0.0-rc.1",
+		},
+		{
+			"name":            "1.1.0",
+			"time":            "2023-02-01T00:00:00.000Z",
+			"deprecated":      "",
+			"dist-tags":       "latest stable",
+			"dist-tag-latest": "1.1.0",
+			"dist-tag-stable": "1.1.0",
+			"dist-tag-next":   "2.0.0-rc.1",
+		},
+		{
+			"name":            "1.0.0",
+			"time":            "2023-01-01T00:00:00.000Z",
+			"deprecated":      "use 1.1.0",
+			"dist-tags":       "",
+			"dist-tag-latest": "1.1.0",
+			"dist-tag-stable": "1.1.0",
+			"dist-tag-next":   "2.0.0-rc.1",
+		},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	f, err = npm.New(npm.Name, ts.URL+":missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != "error response: 404 Not Found" {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
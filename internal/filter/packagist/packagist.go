@@ -0,0 +1,172 @@
+package packagist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://repo.packagist.org/p2/monolog/monolog.json
+// minified, each version only has fields that changed from the previous one
+// and "__unset" removes a field
+/*
+{
+  "minified": "composer/2.0",
+  "packages": {
+    "monolog/monolog": [
+      {
+        "name": "monolog/monolog",
+        "version": "3.5.0",
+        "version_normalized": "3.5.0.0",
+        "license": ["MIT"],
+        "time": "2023-10-27T15:32:31+00:00",
+        ...
+      },
+      {
+        "version": "3.4.0",
+        "version_normalized": "3.4.0.0",
+        "time": "2023-06-21T08:46:11+00:00"
+      },
+      ...
+    ]
+  }
+}
+*/
+
+const defaultRepo = "https://repo.packagist.org"
+
+// Name of filter
+const Name = "packagist"
+
+// Help text
+var Help = `
+packagist:<vendor>/<package> or packagist:<repo>:<vendor>/<package>
+
+Produce versions from a composer repository like packagist. Name will be the
+version. Other keys are published (release time) and license (space
+separated).
+
+Versions are ordered as in the repository, usually newest first. Default
+repository is https://repo.packagist.org, can be a mirror that supports the
+composer v2 metadata-url format (p2/<vendor>/<package>.json).
+
+packagist:monolog/monolog|^3
+packagist:https://repo.packagist.org:symfony/console|^6|@published
+`[1:]
+
+// New packagist filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a package name")
+	}
+
+	repo, package_, ok := filter.SplitURLArg(arg)
+	if parts := strings.Split(package_, "/"); !ok || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("should be packagist:<vendor>/<package> or packagist:<repo>:<vendor>/<package>")
+	}
+
+	return packagistFilter{
+		repo:     repo,
+		package_: package_,
+	}, nil
+}
+
+type packagistFilter struct {
+	repo     string
+	package_ string
+}
+
+func (f packagistFilter) String() string {
+	if f.repo != "" {
+		return Name + ":" + f.repo + ":" + f.package_
+	}
+	return Name + ":" + f.package_
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// expand minified versions, each version inherits fields from the previous
+// https://github.com/composer/metadata-minifier
+func expand(minified []map[string]any) []map[string]any {
+	var expanded []map[string]any
+	var prev map[string]any
+	for _, m := range minified {
+		e := map[string]any{}
+		for k, v := range prev {
+			e[k] = v
+		}
+		for k, v := range m {
+			if v == "__unset" {
+				delete(e, k)
+				continue
+			}
+			e[k] = v
+		}
+		expanded = append(expanded, e)
+		prev = e
+	}
+	return expanded
+}
+
+func (f packagistFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	repo := f.repo
+	if repo == "" {
+		repo = defaultRepo
+	}
+
+	r, err := http.Get(strings.TrimSuffix(repo, "/") + "/p2/" + strings.ToLower(f.package_) + ".json")
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	var response struct {
+		Minified string                      `json:"minified"`
+		Packages map[string][]map[string]any `json:"packages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, "", err
+	}
+	packageVersions, ok := response.Packages[strings.ToLower(f.package_)]
+	if !ok {
+		return nil, "", fmt.Errorf("package %q not found", f.package_)
+	}
+	if response.Minified != "" {
+		packageVersions = expand(packageVersions)
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, pv := range packageVersions {
+		var licenses []string
+		if ls, ok := pv["license"].([]any); ok {
+			for _, l := range ls {
+				licenses = append(licenses, str(l))
+			}
+		}
+		published := str(pv["time"])
+		if t, err := time.Parse(time.RFC3339, published); err == nil {
+			published = t.UTC().Format(time.RFC3339)
+		}
+
+		vs = append(vs, filter.NewVersionWithName(str(pv["version"]), map[string]string{
+			"published": published,
+			"license":   strings.Join(licenses, " "),
+		}))
+	}
+
+	return vs, versionKey, nil
+}
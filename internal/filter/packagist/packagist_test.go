@@ -0,0 +1,47 @@
+package packagist_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/packagist"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/p2/vendor/package.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{
+  "minified": "composer/2.0",
+  "packages": {
+    "vendor/package": [
+      {"name": "vendor/package", "version": "2.0.0", "license": ["MIT", "GPL-2.0-only"], "time": "2023-10-27T15:32:31+02:00"},
+      {"version": "1.1.0", "license": ["MIT"], "time": "2023-06-21T08:46:11+00:00"},
+      {"version": "1.0.0", "time": "__unset"}
+    ]
+  }
+}`)
+	}))
+	defer ts.Close()
+
+	f, err := packagist.New(packagist.Name, ts.URL+":Vendor/Package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "2.0.0", "published": "2023-10-27T13:32:31Z", "license": "MIT GPL-2.0-only"},
+		{"name": "1.1.0", "published": "2023-06-21T08:46:11Z", "license": "MIT"},
+		{"name": "1.0.0", "published": "", "license": "MIT"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+}
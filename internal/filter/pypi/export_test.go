@@ -0,0 +1,5 @@
+package pypi
+
+var FilenameVersion = filenameVersion
+var Normalize = normalize
+var JSONAPIBaseURL = &jsonAPIBaseURL
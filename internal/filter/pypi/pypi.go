@@ -0,0 +1,300 @@
+package pypi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// https://docs.pypi.org/api/json/
+var jsonAPIBaseURL = "https://pypi.org/pypi"
+
+// https://peps.python.org/pep-0691/
+const simpleJSONContentType = "application/vnd.pypi.simple.v1+json"
+
+// Name of filter
+const Name = "pypi"
+
+// Help text
+var Help = `
+pypi:<project> or pypi:<index>:<project>
+
+Produce versions from PyPI JSON API or from a PEP 691 JSON simple index if
+an index URL is provided or PIP_INDEX_URL is set. Name will be the version.
+Other keys are yanked (true if all files are yanked), requires_python and
+upload_time (earliest file upload time).
+
+Versions are ordered by upload time, newest first. Index URL is up to the last
+":" and project name can't be only digits. Index has to support PEP 691 JSON.
+Credentials in index URL userinfo are redacted when shown.
+
+pypi:av|*
+pypi:https://pypi.org/simple:av|*
+# skip yanked versions
+pypi:av|@yanked|/^false$/|@name|*
+`[1:]
+
+// New pypi filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a project name")
+	}
+
+	index, project, ok := filter.SplitURLArg(arg)
+	if !ok || strings.Contains(project, "/") {
+		return nil, fmt.Errorf("should be pypi:<project> or pypi:<index>:<project>")
+	}
+
+	return pypiFilter{
+		index:   index,
+		project: project,
+	}, nil
+}
+
+type pypiFilter struct {
+	index   string
+	project string
+}
+
+func (f pypiFilter) String() string {
+	if f.index != "" {
+		return Name + ":" + filter.RedactURL(f.index) + ":" + f.project
+	}
+	return Name + ":" + f.project
+}
+
+// file is a release file from either the JSON API or a simple index
+type file struct {
+	version        string
+	yanked         bool
+	requiresPython string
+	uploadTime     string
+}
+
+func get(rawURL string, accept string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// http.Client only redacts password, index URL can have a token as user
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			ue.URL = filter.RedactURL(ue.URL)
+		}
+		return err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return fmt.Errorf("error response: %s", r.Status)
+	}
+	// PEP 503 only index ignores accept and responds with HTML
+	if ct := r.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/html") {
+		return fmt.Errorf("%s: got HTML response, index should support PEP 691 JSON (%s)", filter.RedactURL(rawURL), simpleJSONContentType)
+	}
+
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func (f pypiFilter) jsonAPIFiles() ([]string, []file, error) {
+	var response struct {
+		Releases map[string][]struct {
+			Yanked            bool   `json:"yanked"`
+			RequiresPython    string `json:"requires_python"`
+			UploadTimeISO8601 string `json:"upload_time_iso_8601"`
+		} `json:"releases"`
+	}
+
+	if err := get(jsonAPIBaseURL+"/"+normalize(f.project)+"/json", "", &response); err != nil {
+		return nil, nil, err
+	}
+
+	var versions []string
+	var files []file
+	for v, rfs := range response.Releases {
+		versions = append(versions, v)
+		for _, rf := range rfs {
+			files = append(files, file{
+				version:        v,
+				yanked:         rf.Yanked,
+				requiresPython: rf.RequiresPython,
+				uploadTime:     rf.UploadTimeISO8601,
+			})
+		}
+	}
+
+	return versions, files, nil
+}
+
+func (f pypiFilter) simpleFiles(index string) ([]string, []file, error) {
+	var response struct {
+		// versions was added in PEP 700 so might be missing
+		Versions []string `json:"versions"`
+		Files    []struct {
+			Filename       string `json:"filename"`
+			RequiresPython string `json:"requires-python"`
+			// false, true or a reason string
+			Yanked     any    `json:"yanked"`
+			UploadTime string `json:"upload-time"`
+		} `json:"files"`
+	}
+
+	rawURL := strings.TrimSuffix(index, "/") + "/" + normalize(f.project) + "/"
+	if err := get(rawURL, simpleJSONContentType, &response); err != nil {
+		return nil, nil, err
+	}
+
+	versions := response.Versions
+	var files []file
+	for _, sf := range response.Files {
+		v, ok := filenameVersion(sf.Filename)
+		if !ok {
+			continue
+		}
+		yanked := false
+		switch y := sf.Yanked.(type) {
+		case bool:
+			yanked = y
+		case string:
+			yanked = true
+		}
+		files = append(files, file{
+			version:        v,
+			yanked:         yanked,
+			requiresPython: sf.RequiresPython,
+			uploadTime:     sf.UploadTime,
+		})
+	}
+
+	return versions, files, nil
+}
+
+func (f pypiFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	index := f.index
+	if index == "" {
+		index = os.Getenv("PIP_INDEX_URL")
+	}
+
+	var pypiVersions []string
+	var files []file
+	var err error
+	if index != "" {
+		pypiVersions, files, err = f.simpleFiles(index)
+	} else {
+		pypiVersions, files, err = f.jsonAPIFiles()
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	type versionFiles struct {
+		files          int
+		yankedFiles    int
+		requiresPython string
+		uploadTime     string
+	}
+	vfs := map[string]*versionFiles{}
+	var order []string
+	addVersion := func(v string) *versionFiles {
+		vf, ok := vfs[v]
+		if !ok {
+			vf = &versionFiles{}
+			vfs[v] = vf
+			order = append(order, v)
+		}
+		return vf
+	}
+	for _, v := range pypiVersions {
+		addVersion(v)
+	}
+	for _, pf := range files {
+		vf := addVersion(pf.version)
+		vf.files++
+		if pf.yanked {
+			vf.yankedFiles++
+		}
+		if vf.requiresPython == "" {
+			vf.requiresPython = pf.requiresPython
+		}
+		if vf.uploadTime == "" || (pf.uploadTime != "" && pf.uploadTime < vf.uploadTime) {
+			vf.uploadTime = pf.uploadTime
+		}
+	}
+
+	var pypiVs filter.Versions
+	for _, v := range order {
+		vf := vfs[v]
+		pypiVs = append(pypiVs, filter.NewVersionWithName(v, map[string]string{
+			"yanked":          strconv.FormatBool(vf.files > 0 && vf.files == vf.yankedFiles),
+			"requires_python": vf.requiresPython,
+			"upload_time":     vf.uploadTime,
+		}))
+	}
+	// upload time is RFC 3339 in UTC so can be compared as strings
+	sort.Slice(pypiVs, func(i, j int) bool {
+		if pypiVs[i]["upload_time"] == pypiVs[j]["upload_time"] {
+			return pypiVs[i]["name"] > pypiVs[j]["name"]
+		}
+		return pypiVs[i]["upload_time"] > pypiVs[j]["upload_time"]
+	})
+
+	vs := append(filter.Versions{}, versions...)
+	vs = append(vs, pypiVs...)
+
+	return vs, versionKey, nil
+}
+
+var normalizeRe = regexp.MustCompile(`[-_.]+`)
+
+// normalize project name
+// https://packaging.python.org/en/latest/specifications/name-normalization/
+func normalize(name string) string {
+	return strings.ToLower(normalizeRe.ReplaceAllString(name, "-"))
+}
+
+var sdistExts = []string{".tar.gz", ".tar.bz2", ".tar.xz", ".tgz", ".zip", ".tar"}
+
+// filenameVersion finds version in a distribution filename
+// wheel and egg: <name>-<version>-<tags...>.whl
+// sdist: <name>-<version>.tar.gz
+func filenameVersion(filename string) (string, bool) {
+	if strings.HasSuffix(filename, ".whl") || strings.HasSuffix(filename, ".egg") {
+		parts := strings.Split(filename, "-")
+		if len(parts) < 3 {
+			return "", false
+		}
+		return parts[1], true
+	}
+
+	for _, ext := range sdistExts {
+		if !strings.HasSuffix(filename, ext) {
+			continue
+		}
+		stem := strings.TrimSuffix(filename, ext)
+		n := strings.LastIndex(stem, "-")
+		if n == -1 {
+			return "", false
+		}
+		return stem[n+1:], true
+	}
+
+	return "", false
+}
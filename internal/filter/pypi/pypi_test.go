@@ -0,0 +1,161 @@
+package pypi_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/pypi"
+)
+
+func TestFilenameVersion(t *testing.T) {
+	testCases := []struct {
+		filename        string
+		expectedVersion string
+		expectedOK      bool
+	}{
+		{filename: "av-12.0.0-cp39-cp39-manylinux_2_17_x86_64.whl", expectedVersion: "12.0.0", expectedOK: true},
+		{filename: "my_project-1.0.post1-py3-none-any.whl", expectedVersion: "1.0.post1", expectedOK: true},
+		{filename: "setuptools-0.6c11-py2.7.egg", expectedVersion: "0.6c11", expectedOK: true},
+		{filename: "av-12.0.0.tar.gz", expectedVersion: "12.0.0", expectedOK: true},
+		{filename: "my-project-1.0rc1.zip", expectedVersion: "1.0rc1", expectedOK: true},
+		{filename: "bad.whl", expectedOK: false},
+		{filename: "noversion.tar.gz", expectedOK: false},
+		{filename: "av-12.0.0.exe", expectedOK: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.filename, func(t *testing.T) {
+			actualVersion, actualOK := pypi.FilenameVersion(tC.filename)
+			if actualVersion != tC.expectedVersion || actualOK != tC.expectedOK {
+				t.Errorf("expected %q %v got %q %v", tC.expectedVersion, tC.expectedOK, actualVersion, actualOK)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for name, expected := range map[string]string{
+		"av":                "av",
+		"Friendly-Bard":     "friendly-bard",
+		"FRIENDLY-BARD":     "friendly-bard",
+		"friendly.bard":     "friendly-bard",
+		"friendly_bard":     "friendly-bard",
+		"friendly--bard":    "friendly-bard",
+		"FrIeNdLy-._.-bArD": "friendly-bard",
+	} {
+		if actual := pypi.Normalize(name); actual != expected {
+			t.Errorf("%s: expected %q got %q", name, expected, actual)
+		}
+	}
+}
+
+func TestSimpleIndex(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/simple/my-project/":
+			if r.Header.Get("Accept") != "application/vnd.pypi.simple.v1+json" {
+				t.Errorf("unexpected accept %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Content-Type", "application/vnd.pypi.simple.v1+json")
+			fmt.Fprint(w, `{
+	"versions": ["1.0.0", "1.1.0", "1.2.0", "2.0.0"],
+	"files": [
+		{"filename": "my_project-1.0.0.tar.gz", "requires-python": ">=3.7", "yanked": false, "upload-time": "2023-01-01T00:00:00Z"},
+		{"filename": "my_project-1.1.0-py3-none-any.whl", "yanked": "broken", "upload-time": "2023-02-01T00:00:00Z"},
+		{"filename": "my_project-1.1.0.tar.gz", "yanked": true, "upload-time": "2023-02-01T00:00:01Z"},
+		{"filename": "my_project-1.2.0.tar.gz", "yanked": false, "upload-time": "2023-02-01T00:00:00Z"},
+		{"filename": "my_project-2.0.0-py3-none-any.whl", "requires-python": ">=3.9", "upload-time": "2023-03-01T00:00:00Z"},
+		{"filename": "my_project-2.0.0.tar.gz", "yanked": true, "upload-time": "2023-03-02T00:00:00Z"}
+	]
+}`)
+		case "/html/my-project/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body><a href="my_project-1.0.0.tar.gz">my_project-1.0.0.tar.gz</a></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	f, err := pypi.New(pypi.Name, ts.URL+"/simple:My_Project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "2.0.0", "yanked": "false", "requires_python": ">=3.9", "upload_time": "2023-03-01T00:00:00Z"},
+		// same upload time, ordered by version
+		{"name": "1.2.0", "yanked": "false", "requires_python": "", "upload_time": "2023-02-01T00:00:00Z"},
+		{"name": "1.1.0", "yanked": "true", "requires_python": "", "upload_time": "2023-02-01T00:00:00Z"},
+		{"name": "1.0.0", "yanked": "false", "requires_python": ">=3.7", "upload_time": "2023-01-01T00:00:00Z"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	// token in userinfo should be redacted
+	f, err = pypi.New(pypi.Name, strings.Replace(ts.URL, "://", "://user:secret@", 1)+"/html:my-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedErr := strings.Replace(ts.URL, "://", "://user:xxxxx@", 1) + "/html/my-project/: got HTML response, index should support PEP 691 JSON (application/vnd.pypi.simple.v1+json)"
+	if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q got %v", expectedErr, err)
+	}
+}
+
+func TestJSONAPI(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pypi/my-project/json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{
+	"releases": {
+		"1.0.0": [
+			{"yanked": false, "requires_python": ">=3.7", "upload_time_iso_8601": "2023-01-01T00:00:01.000000Z"},
+			{"yanked": false, "requires_python": ">=3.7", "upload_time_iso_8601": "2023-01-01T00:00:00.000000Z"}
+		],
+		"1.1.0": [
+			{"yanked": true, "requires_python": null, "upload_time_iso_8601": "2023-02-01T00:00:00.000000Z"}
+		],
+		"2.0.0rc1": []
+	}
+}`)
+	}))
+	defer ts.Close()
+
+	defaultBaseURL := *pypi.JSONAPIBaseURL
+	*pypi.JSONAPIBaseURL = ts.URL + "/pypi"
+	defer func() { *pypi.JSONAPIBaseURL = defaultBaseURL }()
+	t.Setenv("PIP_INDEX_URL", "")
+
+	f, err := pypi.New(pypi.Name, "My.Project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "1.1.0", "yanked": "true", "requires_python": "", "upload_time": "2023-02-01T00:00:00.000000Z"},
+		{"name": "1.0.0", "yanked": "false", "requires_python": ">=3.7", "upload_time": "2023-01-01T00:00:00.000000Z"},
+		// no files so no upload time
+		{"name": "2.0.0rc1", "yanked": "false", "requires_python": "", "upload_time": ""},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+
+	f, err = pypi.New(pypi.Name, "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = f.Filter(nil, "name"); err == nil || err.Error() != "error response: 404 Not Found" {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package rubygems
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wader/bump/internal/filter"
+)
+
+// curl https://rubygems.org/api/v1/versions/rails.json
+// yanked versions are not included
+/*
+[
+  {
+    "number": "7.1.2",
+    "platform": "ruby",
+    "created_at": "2023-11-10T21:51:00.532Z",
+    "licenses": ["MIT"],
+    "prerelease": false,
+    ...
+  },
+  ...
+]
+*/
+
+const defaultHost = "https://rubygems.org"
+
+// Name of filter
+const Name = "rubygems"
+
+// Help text
+var Help = `
+rubygems:<gem> or rubygems:<host>:<gem>
+
+Produce versions from a RubyGems host. Name will be the version. Other keys
+are published (release time), license (space separated) and platform (like
+"ruby" or "x86_64-linux", same version can appear once per platform). Yanked
+versions are not listed.
+
+Versions are ordered as listed by the host, usually newest first. Default host
+is https://rubygems.org.
+
+rubygems:rails|^7
+rubygems:nokogiri|@platform|/^ruby$/|@name|^1
+rubygems:https://rubygems.org:rake|*
+`[1:]
+
+// New rubygems filter
+func New(prefix string, arg string) (_ filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("needs a gem name")
+	}
+
+	host, gem, ok := filter.SplitURLArg(arg)
+	if !ok || strings.Contains(gem, "/") {
+		return nil, fmt.Errorf("should be rubygems:<gem> or rubygems:<host>:<gem>")
+	}
+
+	return rubygemsFilter{
+		host: host,
+		gem:  gem,
+	}, nil
+}
+
+type rubygemsFilter struct {
+	host string
+	gem  string
+}
+
+func (f rubygemsFilter) String() string {
+	if f.host != "" {
+		return Name + ":" + f.host + ":" + f.gem
+	}
+	return Name + ":" + f.gem
+}
+
+func (f rubygemsFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	host := f.host
+	if host == "" {
+		host = defaultHost
+	}
+
+	r, err := http.Get(strings.TrimSuffix(host, "/") + "/api/v1/versions/" + url.PathEscape(f.gem) + ".json")
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("error response: %s", r.Status)
+	}
+
+	var response []struct {
+		Number    string   `json:"number"`
+		Platform  string   `json:"platform"`
+		CreatedAt string   `json:"created_at"`
+		Licenses  []string `json:"licenses"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, "", err
+	}
+
+	vs := append(filter.Versions{}, versions...)
+	for _, v := range response {
+		published := v.CreatedAt
+		if t, err := time.Parse(time.RFC3339, published); err == nil {
+			published = t.UTC().Format(time.RFC3339)
+		}
+
+		vs = append(vs, filter.NewVersionWithName(v.Number, map[string]string{
+			"published": published,
+			"license":   strings.Join(v.Licenses, " "),
+			"platform":  v.Platform,
+		}))
+	}
+
+	return vs, versionKey, nil
+}
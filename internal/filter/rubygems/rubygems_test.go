@@ -0,0 +1,42 @@
+package rubygems_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/filter/rubygems"
+)
+
+func TestFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/versions/gem.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+  {"number": "1.1.0", "platform": "x86_64-linux", "created_at": "2023-11-10T21:51:00.532Z", "licenses": ["MIT"], "prerelease": false},
+  {"number": "1.1.0", "platform": "ruby", "created_at": "2023-11-10T21:50:00.000Z", "licenses": ["MIT"], "prerelease": false},
+  {"number": "1.0.0", "platform": "ruby", "created_at": "2022-01-01T00:00:00.000Z", "licenses": null, "prerelease": false}
+]`)
+	}))
+	defer ts.Close()
+
+	f, err := rubygems.New(rubygems.Name, ts.URL+":gem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := f.Filter(nil, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := filter.Versions{
+		{"name": "1.1.0", "published": "2023-11-10T21:51:00Z", "license": "MIT", "platform": "x86_64-linux"},
+		{"name": "1.1.0", "published": "2023-11-10T21:50:00Z", "license": "MIT", "platform": "ruby"},
+		{"name": "1.0.0", "published": "2022-01-01T00:00:00Z", "license": "", "platform": "ruby"},
+	}
+	deepequal.Error(t, "versions", expected, actual)
+}
@@ -0,0 +1,92 @@
+package xpath
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/xpath"
+)
+
+// Name of filter
+const Name = "xpath"
+
+// Help text
+var Help = `
+xpath:<expr>
+
+Parse value of current key as XML and produce one version per node selected by
+a XPath expression. Name will be the text content of the node. For elements
+child elements text and attributes will be keys, for attributes the keys will
+be from the element it belongs to.
+
+Supports a subset of XPath: /a/b, //b, *, ., .., @attr, @*, text() and
+predicates [n], [last()], [@attr], [name], [@attr='v'], [name!='v'],
+[contains(@attr,'v')] and [starts-with(name,'v')]. Namespace prefixes are
+ignored.
+
+fetch:https://repo.maven.apache.org/maven2/log4j/log4j/maven-metadata.xml|xpath://version
+fetch:https://repo.maven.apache.org/maven2/log4j/log4j/maven-metadata.xml|xpath:/metadata/versioning/release
+`[1:]
+
+// New xpath filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	expr, err := xpath.Parse(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return xpathFilter{exprStr: arg, expr: expr}, nil
+}
+
+type xpathFilter struct {
+	exprStr string
+	expr    xpath.Expr
+}
+
+func (f xpathFilter) String() string {
+	return Name + ":" + f.exprStr
+}
+
+// keys from child elements text and attributes, first child wins
+func elementKeys(n *xpath.Node) map[string]string {
+	keys := map[string]string{}
+	for _, c := range n.Children {
+		if c.Type != xpath.ElementNode {
+			continue
+		}
+		if _, ok := keys[c.Name]; !ok {
+			keys[c.Name] = strings.TrimSpace(c.Text())
+		}
+	}
+	for _, a := range n.Attrs {
+		keys[a.Name] = a.Data
+	}
+	return keys
+}
+
+func (f xpathFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	var vs filter.Versions
+	for _, v := range versions {
+		doc, err := xpath.Decode(strings.NewReader(v[versionKey]))
+		if err != nil {
+			return nil, "", fmt.Errorf("xml: %w", err)
+		}
+
+		for _, n := range f.expr.Select(doc) {
+			var keys map[string]string
+			switch n.Type {
+			case xpath.ElementNode:
+				keys = elementKeys(n)
+			case xpath.AttributeNode:
+				keys = elementKeys(n.Parent)
+			}
+			vs = append(vs, filter.NewVersionWithName(strings.TrimSpace(n.Text()), keys))
+		}
+	}
+
+	return vs, "name", nil
+}
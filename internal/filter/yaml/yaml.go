@@ -0,0 +1,68 @@
+package yaml
+
+import (
+	"fmt"
+
+	"github.com/wader/bump/internal/filter"
+	"github.com/wader/bump/internal/jsonpath"
+	"github.com/wader/bump/internal/yaml"
+)
+
+// Name of filter
+const Name = "yaml"
+
+// Help text
+var Help = `
+yaml:<path>
+
+Parse value of current key as YAML and produce one version per element
+selected by path. Works like the json filter with same path syntax, mapping
+fields will be keys and nested mappings and sequences will be compact JSON.
+All scalars are strings and only the first document is used.
+
+fetch:https://charts.bitnami.com/bitnami/index.yaml|yaml:.entries.nginx[*]|@version
+fetch:https://charts.bitnami.com/bitnami/index.yaml|yaml:.entries.nginx[0]|@appVersion
+`[1:]
+
+// New yaml filter
+func New(prefix string, arg string) (filter filter.Filter, err error) {
+	if prefix != Name {
+		return nil, nil
+	}
+	path, err := jsonpath.Parse(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return yamlFilter{pathStr: arg, path: path}, nil
+}
+
+type yamlFilter struct {
+	pathStr string
+	path    jsonpath.Path
+}
+
+func (f yamlFilter) String() string {
+	return Name + ":" + f.pathStr
+}
+
+func (f yamlFilter) Filter(versions filter.Versions, versionKey string) (filter.Versions, string, error) {
+	var vs filter.Versions
+	for _, v := range versions {
+		root, err := yaml.Unmarshal([]byte(v[versionKey]))
+		if err != nil {
+			return nil, "", fmt.Errorf("yaml: %w", err)
+		}
+
+		for _, sv := range f.path.Select(root) {
+			fields := jsonpath.Fields(sv)
+			name, ok := fields["name"]
+			if !ok {
+				name = jsonpath.String(sv)
+			}
+			vs = append(vs, filter.NewVersionWithName(name, fields))
+		}
+	}
+
+	return vs, "name", nil
+}
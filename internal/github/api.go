@@ -316,6 +316,38 @@ type Comment struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type Release struct {
+	URL             string    `json:"url"`
+	HTMLURL         string    `json:"html_url"`
+	ID              int       `json:"id"`
+	NodeID          string    `json:"node_id"`
+	TagName         string    `json:"tag_name"`
+	TargetCommitish string    `json:"target_commitish"`
+	Name            string    `json:"name"`
+	Body            string    `json:"body"`
+	Draft           bool      `json:"draft"`
+	Prerelease      bool      `json:"prerelease"`
+	CreatedAt       time.Time `json:"created_at"`
+	PublishedAt     time.Time `json:"published_at"`
+	Author          User      `json:"author"`
+	Assets          []Asset   `json:"assets"`
+}
+
+type Asset struct {
+	URL                string    `json:"url"`
+	BrowserDownloadURL string    `json:"browser_download_url"`
+	ID                 int       `json:"id"`
+	NodeID             string    `json:"node_id"`
+	Name               string    `json:"name"`
+	Label              string    `json:"label"`
+	State              string    `json:"state"`
+	ContentType        string    `json:"content_type"`
+	Size               int       `json:"size"`
+	DownloadCount      int       `json:"download_count"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
 type Client struct {
 	BaseURL    string
 	Token      string
@@ -332,6 +364,10 @@ func (c *Client) URL(path string, params []string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
+	// make path relative to base URL path, https://host/api/v3 -> https://host/api/v3/
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
 	if len(params)%2 != 0 {
 		return nil, fmt.Errorf("params should be pairs")
 	}
@@ -348,10 +384,6 @@ func (c *Client) URL(path string, params []string) (*url.URL, error) {
 }
 
 func (c *Client) Do(method, path string, params []string, body any, out any) error {
-	if c.Token == "" {
-		return fmt.Errorf("token not set")
-	}
-
 	u, err := c.URL(path, params)
 	if err != nil {
 		return err
@@ -374,7 +406,11 @@ func (c *Client) Do(method, path string, params []string, body any, out any) err
 	// https://developer.github.com/v3/#user-agent-required
 	req.Header.Set("User-Agent", "https://github.com/wader/bump "+c.Version)
 	req.Header.Add("Accept", "application/vnd.github.v3+json")
-	req.Header.Add("Authorization", "token "+c.Token)
+	// token is optional for read only requests to public repos, but
+	// unauthenticated requests are more rate limited
+	if c.Token != "" {
+		req.Header.Add("Authorization", "token "+c.Token)
+	}
 
 	if body != nil {
 		req.Header.Add("Content-Type", "application/json")
@@ -457,3 +493,9 @@ func (repo *RepoRef) CreateComment(prNumber int, com NewComment) (Comment, error
 	err := repo.c.Do("POST", fmt.Sprintf("repos/%s/issues/%d/comments", repo.Name, prNumber), nil, com, &outCom)
 	return outCom, err
 }
+
+func (repo *RepoRef) ListReleases(params ...string) ([]Release, error) {
+	var outReleases []Release
+	err := repo.c.Do("GET", fmt.Sprintf("repos/%s/releases", repo.Name), params, nil, &outReleases)
+	return outReleases, err
+}
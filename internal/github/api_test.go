@@ -122,6 +122,51 @@ func TestListPullRequest(t *testing.T) {
 	}
 }
 
+func TestListReleases(t *testing.T) {
+	expectedReleases := []github.Release{
+		{ID: 123, TagName: "v1.0.1", Prerelease: true, Assets: []github.Asset{{Name: "a.tar.gz"}}},
+		{ID: 456, TagName: "v1.0.0"},
+	}
+
+	c := &github.Client{
+		HTTPClient: responseClient(func(req *http.Request) (any, int) {
+			type c struct {
+				Method        string
+				ParamPage     string
+				Path          string
+				Authorization string
+			}
+
+			expectedC := c{
+				Method:        "GET",
+				ParamPage:     "2",
+				Path:          "/repos/user/repo/releases",
+				Authorization: "",
+			}
+			actualC := c{
+				Method:        req.Method,
+				ParamPage:     req.URL.Query().Get("page"),
+				Path:          req.URL.Path,
+				Authorization: req.Header.Get("Authorization"),
+			}
+
+			if expectedC != actualC {
+				t.Errorf("expected %#v, got %#v", expectedC, actualC)
+			}
+
+			return expectedReleases, 200
+		}),
+	}
+
+	actualReleases, err := c.NewRepoRef("user/repo").ListReleases("page", "2")
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(expectedReleases, actualReleases) {
+		t.Errorf("expected releases %#v, got %#v", expectedReleases, actualReleases)
+	}
+}
+
 func TestCreatePullRequest(t *testing.T) {
 	expectedNewPR := github.NewPullRequest{
 		Title:               "a",
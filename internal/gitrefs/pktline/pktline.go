@@ -1,8 +1,10 @@
 // Package pktline implements git pktline format
 // https://github.com/git/git/blob/master/Documentation/technical/pack-protocol.txt
+// https://github.com/git/git/blob/master/Documentation/technical/protocol-v2.txt
 // Encoded as hexlen + string where len is 16 bit hex encoded len(string) + len(hexlen)
 // Ex: "a" is "0005a"
 // Ex: "" is "0000" (special case)
+// Protocol v2 also has special "0001" delimiter and "0002" response end packets
 package pktline
 
 import (
@@ -12,34 +14,85 @@ import (
 	"io"
 )
 
-// Read a pktline
-func Read(r io.Reader) (string, error) {
+// Type of packet
+type Type int
+
+// Packet types
+const (
+	Data Type = iota
+	Flush
+	Delim
+	ResponseEnd
+)
+
+func (t Type) String() string {
+	switch t {
+	case Data:
+		return "data"
+	case Flush:
+		return "flush"
+	case Delim:
+		return "delim"
+	case ResponseEnd:
+		return "response-end"
+	}
+	return "unknown"
+}
+
+// Special packets
+const (
+	FlushPkt       = "0000"
+	DelimPkt       = "0001"
+	ResponseEndPkt = "0002"
+)
+
+// ReadPacket reads a packet, string is empty if not a data packet
+func ReadPacket(r io.Reader) (Type, string, error) {
 	var err error
 
 	var lenHexBuf [4]byte
 	_, err = io.ReadFull(r, lenHexBuf[:])
 	if err != nil {
-		return "", err
+		return Data, "", err
 	}
 	var lenBuf [2]byte
 	_, err = hex.Decode(lenBuf[:], lenHexBuf[:])
 	if err != nil {
-		return "", err
+		return Data, "", err
 	}
 	l := binary.BigEndian.Uint16(lenBuf[:])
-	if l == 0 {
-		return "", nil
+	switch l {
+	case 0:
+		return Flush, "", nil
+	case 1:
+		return Delim, "", nil
+	case 2:
+		return ResponseEnd, "", nil
 	}
 	if l < 4 {
-		return "", fmt.Errorf("short len %d", l)
+		return Data, "", fmt.Errorf("short len %d", l)
 	}
 	lineBuf := make([]byte, l-4)
 	_, err = io.ReadFull(r, lineBuf[:])
 	if err != nil {
-		return "", err
+		return Data, "", err
 	}
 
-	return string(lineBuf), nil
+	return Data, string(lineBuf), nil
+}
+
+// Read a pktline, flush is empty string, other special packets are errors
+func Read(r io.Reader) (string, error) {
+	t, s, err := ReadPacket(r)
+	if err != nil {
+		return "", err
+	}
+	switch t {
+	case Data, Flush:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unexpected %s packet", t)
+	}
 }
 
 // Write a pktline
@@ -50,7 +103,7 @@ func Write(w io.Writer, s string) (int, error) {
 // Encode a pktline
 func Encode(s string) []byte {
 	if len(s) == 0 {
-		return []byte("0000")
+		return []byte(FlushPkt)
 	}
 
 	return []byte(fmt.Sprintf("%04x%s", uint16(len(s)+4), s))
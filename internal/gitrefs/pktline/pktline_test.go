@@ -57,3 +57,40 @@ func Test(t *testing.T) {
 		})
 	}
 }
+
+func TestReadPacket(t *testing.T) {
+	testCases := []struct {
+		pktLine      []byte
+		expectedType pktline.Type
+		expectedLine string
+		expectedErr  string
+	}{
+		{[]byte("0000"), pktline.Flush, "", ""},
+		{[]byte("0001"), pktline.Delim, "", ""},
+		{[]byte("0002"), pktline.ResponseEnd, "", ""},
+		{[]byte("0003"), pktline.Data, "", "short len 3"},
+		{[]byte("0008ls\n\n"), pktline.Data, "ls\n\n", ""},
+	}
+	for _, tC := range testCases {
+		tC := tC
+		t.Run(string(tC.pktLine), func(t *testing.T) {
+			actualType, actualLine, err := pktline.ReadPacket(bytes.NewReader(tC.pktLine))
+			if tC.expectedErr != "" {
+				if err == nil || err.Error() != tC.expectedErr {
+					t.Fatalf("expected error %q got %v", tC.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tC.expectedType != actualType || tC.expectedLine != actualLine {
+				t.Errorf("expected %s %q got %s %q", tC.expectedType, tC.expectedLine, actualType, actualLine)
+			}
+		})
+	}
+
+	if _, err := pktline.Read(bytes.NewReader([]byte("0001"))); err == nil || err.Error() != "unexpected delim packet" {
+		t.Errorf("expected unexpected delim packet error got %v", err)
+	}
+}
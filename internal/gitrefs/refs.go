@@ -1,10 +1,12 @@
 // Package gitrefs gets refs from a git repo (like git ls-remote)
 // https://github.com/git/git/blob/master/Documentation/technical/http-protocol.txt
 // https://github.com/git/git/blob/master/Documentation/technical/pack-protocol.txt
+// https://github.com/git/git/blob/master/Documentation/technical/protocol-v2.txt
 package gitrefs
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -12,10 +14,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/wader/bump/internal/filter"
 	"github.com/wader/bump/internal/gitrefs/pktline"
 )
 
@@ -23,7 +28,7 @@ const gitPort = 9418
 
 // AllProtos all protocols
 // FileProto might be dangerous if you don't control the url
-var AllProtos = []Proto{HTTPProto{}, GitProto{}, FileProto{}}
+var AllProtos = []Proto{HTTPProto{}, GitProto{}, SSHProto{}, FileProto{}}
 
 // Ref is name/object id pair
 type Ref struct {
@@ -33,21 +38,66 @@ type Ref struct {
 
 // Refs fetches refs for a remote repo (like git ls-remote)
 func Refs(rawurl string, protos []Proto) ([]Ref, error) {
-	u, err := url.Parse(rawurl)
+	return RefsWithPrefixes(rawurl, nil, protos)
+}
+
+// RefsWithPrefixes fetches refs for a remote repo with a name starting with
+// one of prefixes, all refs if no prefixes (like git ls-remote --refs <prefix>)
+// Prefixes are sent to the server if it supports protocol v2 otherwise filtered
+// after fetching all refs.
+func RefsWithPrefixes(rawurl string, prefixes []string, protos []Proto) ([]Ref, error) {
+	u, err := ParseURL(rawurl)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, p := range protos {
-		refs, err := p.Refs(u)
+		refs, err := p.Refs(u, prefixes)
 		if err == nil && refs == nil {
 			continue
 		}
+		if err != nil {
+			return nil, err
+		}
 
-		return refs, err
+		return filterPrefixes(refs, prefixes), nil
 	}
 
-	return nil, fmt.Errorf("unknown url: %s", rawurl)
+	return nil, fmt.Errorf("unknown url: %s", filter.RedactURL(rawurl))
+}
+
+// scp-like syntax [user@]host:path
+var scpLikeRe = regexp.MustCompile(`^(?:([^@/]+)@)?([^@/:]+):(.*)$`)
+
+// ParseURL parses url or scp-like syntax as ssh url with path as is
+// git@host:org/repo.git -> ssh://git@host with path "org/repo.git"
+func ParseURL(rawurl string) (*url.URL, error) {
+	if !strings.Contains(rawurl, "://") {
+		if sm := scpLikeRe.FindStringSubmatch(rawurl); sm != nil {
+			u := &url.URL{Scheme: "ssh", Host: sm[2], Path: sm[3]}
+			if sm[1] != "" {
+				u.User = url.User(sm[1])
+			}
+			return u, nil
+		}
+	}
+	return parseUserinfoURL(rawurl)
+}
+
+func filterPrefixes(refs []Ref, prefixes []string) []Ref {
+	if len(prefixes) == 0 {
+		return refs
+	}
+	filtered := []Ref{}
+	for _, r := range refs {
+		for _, p := range prefixes {
+			if strings.HasPrefix(r.Name, p) {
+				filtered = append(filtered, r)
+				break
+			}
+		}
+	}
+	return filtered
 }
 
 // HEAD\0multi_ack thin-pack -> HEAD
@@ -60,49 +110,189 @@ func refName(s string) string {
 	return s[0:n]
 }
 
-// GITProtocol talk native git protocol
-// 000eversion 1
-// 00887217a7c7e582c46cec22a130adf4b9d7d950fba0 HEAD\0multi_ack thin-pack side-band side-band-64k ofs-delta shallow no-progress include-tag
-// 00441d3fcd5ced445d1abc402225c0b8a1299641f497 refs/heads/integration
-// 003f7217a7c7e582c46cec22a130adf4b9d7d950fba0 refs/heads/master
-// 003cb88d2441cac0977faf98efc80305012112238d9d refs/tags/v0.9
-// 003c525128480b96c89e6418b1e40909bf6c5b2d580f refs/tags/v1.0
-// 003fe92df48743b7bc7d26bcaabfddde0a1e20cae47c refs/tags/v1.0^{}
-// 0000
-func GITProtocol(u *url.URL, rw io.ReadWriter) ([]Ref, error) {
-	_, err := pktline.Write(rw, fmt.Sprintf("git-upload-pack %s\x00host=%s\x00\x00version=1\x00", u.Path, u.Host))
-	if err != nil {
-		return nil, err
-	}
-
+// readRefsV1 reads v1 ref advertisement lines until flush
+// first is an already read line
+func readRefsV1(first string, r io.Reader) ([]Ref, error) {
 	var refs []Ref
+	line := first
 	for {
-		line, err := pktline.Read(rw)
-		if err != nil {
-			return nil, err
-		}
 		if line == "" {
 			break
 		}
 		line = strings.TrimSpace(line)
 
-		objIDName := strings.SplitN(line, ` `, 2)
+		objIDName := strings.SplitN(line, " ", 2)
 		if len(objIDName) != 2 {
 			return nil, fmt.Errorf("unexpected refs line: %s", line)
 		}
 		objID := objIDName[0]
 		name := refName(objIDName[1])
 
-		if objID == "version" {
+		if objID != "version" {
+			refs = append(refs, Ref{Name: name, ObjID: objID})
+		}
+
+		var err error
+		line, err = pktline.Read(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return refs, nil
+}
+
+// readCapabilitiesV2 reads capability advertisement after "version 2" until flush
+// 000eversion 2
+// 0015agent=git/2.42.0
+// 0013ls-refs=unborn
+// 0012fetch=shallow
+// 0000
+func readCapabilitiesV2(r io.Reader) ([]string, error) {
+	var caps []string
+	for {
+		line, err := pktline.Read(r)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		caps = append(caps, strings.TrimSpace(line))
+	}
+	return caps, nil
+}
+
+func hasCapability(caps []string, name string) bool {
+	for _, c := range caps {
+		if c == name || strings.HasPrefix(c, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLsRefsV2 writes a ls-refs command
+// 0014command=ls-refs
+// 0001
+// 0009peel
+// 001aref-prefix refs/tags/
+// 0000
+func writeLsRefsV2(w io.Writer, prefixes []string) error {
+	lines := []string{"command=ls-refs\n", pktline.DelimPkt, "peel\n"}
+	for _, p := range prefixes {
+		lines = append(lines, "ref-prefix "+p+"\n")
+	}
+	for _, l := range lines {
+		if l == pktline.DelimPkt {
+			if _, err := io.WriteString(w, l); err != nil {
+				return err
+			}
 			continue
 		}
+		if _, err := pktline.Write(w, l); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, pktline.FlushPkt)
+	return err
+}
+
+// readLsRefsV2 reads ls-refs response, peeled tags are returned as "<name>^{}"
+// refs like in v1
+// 00527217a7c7e582c46cec22a130adf4b9d7d950fba0 HEAD symref-target:refs/heads/master
+// 006c525128480b96c89e6418b1e40909bf6c5b2d580f refs/tags/v1.0 peeled:e92df48743b7bc7d26bcaabfddde0a1e20cae47c
+// 0000
+func readLsRefsV2(r io.Reader) ([]Ref, error) {
+	refs := []Ref{}
+	for {
+		t, line, err := pktline.ReadPacket(r)
+		if err != nil {
+			return nil, err
+		}
+		if t == pktline.Flush || t == pktline.ResponseEnd {
+			break
+		} else if t != pktline.Data {
+			return nil, fmt.Errorf("unexpected %s packet", t)
+		}
 
-		refs = append(refs, Ref{Name: name, ObjID: objID})
+		parts := strings.Split(strings.TrimSpace(line), " ")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("unexpected ls-refs line: %s", line)
+		}
+		refs = append(refs, Ref{Name: parts[1], ObjID: parts[0]})
+		for _, attr := range parts[2:] {
+			if peeled, ok := strings.CutPrefix(attr, "peeled:"); ok {
+				refs = append(refs, Ref{Name: parts[1] + "^{}", ObjID: peeled})
+			}
+		}
 	}
 
 	return refs, nil
 }
 
+// advertisedRefs reads v1 refs or v2 capabilities and does ls-refs using send
+// first is an already read line
+func advertisedRefs(first string, r io.Reader, prefixes []string, send func(func(w io.Writer) error) (io.Reader, error)) ([]Ref, error) {
+	if strings.TrimSpace(first) != "version 2" {
+		return readRefsV1(first, r)
+	}
+
+	caps, err := readCapabilitiesV2(r)
+	if err != nil {
+		return nil, err
+	}
+	if !hasCapability(caps, "ls-refs") {
+		return nil, fmt.Errorf("server does not support ls-refs")
+	}
+
+	lr, err := send(func(w io.Writer) error { return writeLsRefsV2(w, prefixes) })
+	if err != nil {
+		return nil, err
+	}
+	return readLsRefsV2(lr)
+}
+
+// GITProtocol talk native git protocol
+// Asks for protocol v2 and does ls-refs with prefixes, falls back to v1 if the
+// server does not support v2
+// 000eversion 1
+// 00887217a7c7e582c46cec22a130adf4b9d7d950fba0 HEAD\0multi_ack thin-pack side-band side-band-64k ofs-delta shallow no-progress include-tag
+// 00441d3fcd5ced445d1abc402225c0b8a1299641f497 refs/heads/integration
+// 003f7217a7c7e582c46cec22a130adf4b9d7d950fba0 refs/heads/master
+// 003cb88d2441cac0977faf98efc80305012112238d9d refs/tags/v0.9
+// 003c525128480b96c89e6418b1e40909bf6c5b2d580f refs/tags/v1.0
+// 003fe92df48743b7bc7d26bcaabfddde0a1e20cae47c refs/tags/v1.0^{}
+// 0000
+func GITProtocol(u *url.URL, rw io.ReadWriter, prefixes []string) ([]Ref, error) {
+	_, err := pktline.Write(rw, fmt.Sprintf("git-upload-pack %s\x00host=%s\x00\x00version=2\x00", u.Path, u.Host))
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadPackRefs(rw, prefixes)
+}
+
+// uploadPackRefs reads refs from a git-upload-pack, v2 is used if advertised
+func uploadPackRefs(rw io.ReadWriter, prefixes []string) ([]Ref, error) {
+	first, err := pktline.Read(rw)
+	if err != nil {
+		return nil, err
+	}
+
+	return advertisedRefs(first, rw, prefixes, func(fn func(w io.Writer) error) (io.Reader, error) {
+		if err := fn(rw); err != nil {
+			return nil, err
+		}
+		if f, ok := rw.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return nil, err
+			}
+		}
+		return rw, nil
+	})
+}
+
 // HTTPSmartProtocol talk git HTTP protocol
 // 001e# service=git-upload-pack\n
 // 0000
@@ -112,43 +302,36 @@ func GITProtocol(u *url.URL, rw io.ReadWriter) ([]Ref, error) {
 // 003fa3c2e2402b99163d1d59756e5f207ae21cccba4c refs/tags/v1.0^{}\n
 // 0000
 func HTTPSmartProtocol(r io.Reader) ([]Ref, error) {
-	// read "# service=git-upload-pack" line
-	_, err := pktline.Read(r)
-	if err != nil {
-		return nil, err
-	}
+	return httpSmartProtocol(r, nil, nil)
+}
 
-	// read section start
+// httpSmartProtocol reads v1 refs or v2 capabilities and then does a ls-refs
+// using send. Service line and flush is optional for v2.
+func httpSmartProtocol(r io.Reader, prefixes []string, send func(func(w io.Writer) error) (io.Reader, error)) ([]Ref, error) {
 	line, err := pktline.Read(r)
 	if err != nil {
 		return nil, err
 	}
-	if line != "" {
-		return nil, fmt.Errorf("unexpected section start line: %s", line)
-	}
-
-	var refs []Ref
-	for {
-		line, err := pktline.Read(r)
+	if strings.HasPrefix(line, "# service=") {
+		// read section start
+		line, err = pktline.Read(r)
 		if err != nil {
 			return nil, err
 		}
-		if line == "" {
-			break
+		if line != "" {
+			return nil, fmt.Errorf("unexpected section start line: %s", line)
 		}
-		line = strings.TrimSpace(line)
-
-		objIDName := strings.SplitN(line, " ", 2)
-		if len(objIDName) != 2 {
-			return nil, fmt.Errorf("unexpected refs line: %s", line)
+		line, err = pktline.Read(r)
+		if err != nil {
+			return nil, err
 		}
-		objID := objIDName[0]
-		name := refName(objIDName[1])
+	}
 
-		refs = append(refs, Ref{Name: name, ObjID: objID})
+	if send == nil && strings.TrimSpace(line) == "version 2" {
+		return nil, fmt.Errorf("protocol v2 not supported")
 	}
 
-	return refs, nil
+	return advertisedRefs(line, r, prefixes, send)
 }
 
 // HTTPDumbProtocol talk git dump HTTP protocol
@@ -176,17 +359,20 @@ func HTTPDumbProtocol(r io.Reader) ([]Ref, error) {
 }
 
 // Proto is a git protocol
+// Prefixes is a hint, refs not matching can be returned
 type Proto interface {
-	Refs(u *url.URL) ([]Ref, error)
+	Refs(u *url.URL, prefixes []string) ([]Ref, error)
 }
 
 // HTTPProto implements git http protocol
+// Credentials are used from URL userinfo, ~/.netrc or if the server responds
+// with 401 Unauthorized from git credential helpers
 type HTTPProto struct {
 	Client *http.Client // http.DefaultClient if nil
 }
 
 // Refs from http repo
-func (h HTTPProto) Refs(u *url.URL) ([]Ref, error) {
+func (h HTTPProto) Refs(u *url.URL, prefixes []string) ([]Ref, error) {
 	if u.Scheme != "http" && u.Scheme != "https" {
 		return nil, nil
 	}
@@ -196,31 +382,91 @@ func (h HTTPProto) Refs(u *url.URL) ([]Ref, error) {
 		client = http.DefaultClient
 	}
 
-	req, err := http.NewRequest(http.MethodGet, u.String()+"/info/refs?service=git-upload-pack", nil)
-	if err != nil {
-		return nil, err
+	// credentials are sent using basic auth to not end up in errors
+	creds := u.User
+	u = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, RawPath: u.RawPath}
+	if creds == nil {
+		creds = netrcCredentials(u.Hostname())
+	}
+	setAuth := func(req *http.Request) {
+		if creds == nil {
+			return
+		}
+		password, _ := creds.Password()
+		req.SetBasicAuth(creds.Username(), password)
+	}
+
+	infoRefs := func() (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, u.String()+"/info/refs?service=git-upload-pack", nil)
+		if err != nil {
+			return nil, err
+		}
+		// some git hosts behave differently based on this, github allows
+		// to skip .git if set for example
+		req.Header.Set("User-Agent", "git/1.0")
+		req.Header.Set("Accept", "*/*")
+		req.Header.Set("Git-Protocol", "version=2")
+		setAuth(req)
+		return client.Do(req)
 	}
-	// some git hosts behave differently based on this, github allows
-	// to skip .git if set for example
-	req.Header.Set("User-Agent", "git/1.0")
-	req.Header.Set("Accept", "*/*")
 
-	resp, err := client.Do(req)
+	resp, err := infoRefs()
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode == http.StatusUnauthorized && creds == nil {
+		if creds = gitCredentialFill(u); creds != nil {
+			resp.Body.Close()
+			resp, err = infoRefs()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 	defer resp.Body.Close()
-	if resp.Header.Get("Content-Type") == "application/x-git-upload-pack-advertisement" {
-		return HTTPSmartProtocol(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("error response: %s", resp.Status)
 	}
-	return HTTPDumbProtocol(resp.Body)
+	if resp.Header.Get("Content-Type") != "application/x-git-upload-pack-advertisement" {
+		return HTTPDumbProtocol(resp.Body)
+	}
+
+	var cmdResp *http.Response
+	defer func() {
+		if cmdResp != nil {
+			cmdResp.Body.Close()
+		}
+	}()
+	return httpSmartProtocol(resp.Body, prefixes, func(fn func(w io.Writer) error) (io.Reader, error) {
+		b := &bytes.Buffer{}
+		if err := fn(b); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodPost, u.String()+"/git-upload-pack", b)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", "git/1.0")
+		req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+		req.Header.Set("Accept", "application/x-git-upload-pack-result")
+		req.Header.Set("Git-Protocol", "version=2")
+		setAuth(req)
+		cmdResp, err = client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if cmdResp.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("git-upload-pack: %s", cmdResp.Status)
+		}
+		return cmdResp.Body, nil
+	})
 }
 
 // GitProto implements gits own protocol
 type GitProto struct{}
 
 // Refs from git repo
-func (GitProto) Refs(u *url.URL) ([]Ref, error) {
+func (GitProto) Refs(u *url.URL, prefixes []string) ([]Ref, error) {
 	if u.Scheme != "git" {
 		return nil, nil
 	}
@@ -234,7 +480,113 @@ func (GitProto) Refs(u *url.URL) ([]Ref, error) {
 		return nil, err
 	}
 	defer n.Close()
-	return GITProtocol(u, n)
+	return GITProtocol(u, n, prefixes)
+}
+
+// SSHProto implements git protocol over ssh for ssh:// and scp-like
+// [user@]host:path repos. Runs GIT_SSH_COMMAND using a shell, GIT_SSH or ssh
+// like git does
+type SSHProto struct{}
+
+// shellQuote single quotes s and escapes single quotes like git does
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sshCommand returns command and arguments to run ssh with args
+func sshCommand(args []string) (string, []string) {
+	if c := os.Getenv("GIT_SSH_COMMAND"); strings.TrimSpace(c) != "" {
+		// GIT_SSH_COMMAND can include arguments, args are passed as positional
+		// arguments and are not interpreted by the shell
+		return "sh", append([]string{"-c", c + ` "$@"`, c}, args...)
+	}
+	if c := os.Getenv("GIT_SSH"); c != "" {
+		return c, args
+	}
+	return "ssh", args
+}
+
+// Refs from ssh repo
+func (SSHProto) Refs(u *url.URL, prefixes []string) ([]Ref, error) {
+	if u.Scheme != "ssh" && u.Scheme != "git+ssh" {
+		return nil, nil
+	}
+
+	// ssh://host/~user/repo -> ~user/repo
+	path := u.Path
+	if strings.HasPrefix(path, "/~") {
+		path = path[1:]
+	}
+
+	// don't allow options to be injected, same as git CVE-2017-1000117
+	host := u.Hostname()
+	if host == "" || strings.HasPrefix(host, "-") {
+		return nil, fmt.Errorf("invalid ssh host: %q", host)
+	}
+	if u.User != nil {
+		if strings.HasPrefix(u.User.Username(), "-") {
+			return nil, fmt.Errorf("invalid ssh user: %q", u.User.Username())
+		}
+		host = u.User.Username() + "@" + host
+	}
+	if strings.HasPrefix(path, "-") {
+		return nil, fmt.Errorf("invalid ssh path: %q", path)
+	}
+
+	program := "ssh"
+	if fields := strings.Fields(os.Getenv("GIT_SSH_COMMAND")); len(fields) > 0 {
+		program = fields[0]
+	} else if c := os.Getenv("GIT_SSH"); c != "" {
+		program = c
+	}
+
+	var args []string
+	// only openssh is known to support SendEnv
+	if filepath.Base(program) == "ssh" {
+		args = append(args, "-o", "SendEnv=GIT_PROTOCOL")
+	}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+	args = append(args, "--", host, "git-upload-pack "+shellQuote(path))
+
+	name, args := sshCommand(args)
+	c := exec.Command(name, args...)
+	c.Env = append(os.Environ(), "GIT_PROTOCOL=version=2")
+	stderr := &bytes.Buffer{}
+	c.Stderr = stderr
+	stdin, err := c.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+
+	refs, refsErr := uploadPackRefs(struct {
+		io.Reader
+		io.Writer
+	}{stdout, stdin}, prefixes)
+	// flush to end session, v1 expects it before wants and v2 as empty request
+	_, _ = io.WriteString(stdin, pktline.FlushPkt)
+	stdin.Close()
+	waitErr := c.Wait()
+
+	if refsErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", refsErr, msg)
+		}
+		return nil, refsErr
+	}
+	if waitErr != nil {
+		return nil, fmt.Errorf("%s: %w", program, waitErr)
+	}
+
+	return refs, nil
 }
 
 func readSymref(gitPath string, p string) (string, error) {
@@ -282,7 +634,7 @@ func readSymref(gitPath string, p string) (string, error) {
 type FileProto struct{}
 
 // Refs from file repo
-func (f FileProto) Refs(u *url.URL) ([]Ref, error) {
+func (f FileProto) Refs(u *url.URL, prefixes []string) ([]Ref, error) {
 	if u.Scheme != "file" {
 		return nil, nil
 	}
@@ -0,0 +1,169 @@
+// Package jsonpath implements a subset of JSONPath to select values decoded
+// by encoding/json or internal/yaml
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepIndex
+	stepWildcard
+)
+
+type step struct {
+	kind  stepKind
+	field string
+	index int
+}
+
+// Path is a parsed path
+type Path []step
+
+// Parse path
+// .field or ["field"] selects an object field
+// [n] selects an array element, negative n counts from the end
+// [*] or .* selects all array elements or object values (sorted by key)
+// empty path, $ or . selects the whole value
+func Parse(s string) (Path, error) {
+	var steps Path
+	p := strings.TrimPrefix(s, "$")
+	if p == "." {
+		return nil, nil
+	}
+	for p != "" {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			if strings.HasPrefix(p, "*") {
+				steps = append(steps, step{kind: stepWildcard})
+				p = p[1:]
+				continue
+			}
+			n := strings.IndexAny(p, ".[")
+			if n == -1 {
+				n = len(p)
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty field name", s)
+			}
+			steps = append(steps, step{kind: stepField, field: p[0:n]})
+			p = p[n:]
+		case '[':
+			n := strings.Index(p, "]")
+			if n == -1 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", s)
+			}
+			sel := p[1:n]
+			p = p[n+1:]
+			switch {
+			case sel == "*":
+				steps = append(steps, step{kind: stepWildcard})
+			case len(sel) >= 2 && (sel[0] == '"' || sel[0] == '\'') && sel[len(sel)-1] == sel[0]:
+				steps = append(steps, step{kind: stepField, field: sel[1 : len(sel)-1]})
+			default:
+				i, err := strconv.Atoi(sel)
+				if err != nil {
+					return nil, fmt.Errorf("invalid path %q: invalid index %q", s, sel)
+				}
+				steps = append(steps, step{kind: stepIndex, index: i})
+			}
+		default:
+			return nil, fmt.Errorf("invalid path %q: expected . or [", s)
+		}
+	}
+
+	return steps, nil
+}
+
+func (s step) apply(v any) []any {
+	switch s.kind {
+	case stepField:
+		if m, ok := v.(map[string]any); ok {
+			if fv, ok := m[s.field]; ok {
+				return []any{fv}
+			}
+		}
+	case stepIndex:
+		if a, ok := v.([]any); ok {
+			i := s.index
+			if i < 0 {
+				i += len(a)
+			}
+			if i >= 0 && i < len(a) {
+				return []any{a[i]}
+			}
+		}
+	case stepWildcard:
+		switch v := v.(type) {
+		case []any:
+			return v
+		case map[string]any:
+			var keys []string
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			var vs []any
+			for _, k := range keys {
+				vs = append(vs, v[k])
+			}
+			return vs
+		}
+	}
+	return nil
+}
+
+// Select values, missing fields or indexes selects nothing
+func (p Path) Select(v any) []any {
+	selected := []any{v}
+	for _, s := range p {
+		var next []any
+		for _, sv := range selected {
+			next = append(next, s.apply(sv)...)
+		}
+		selected = next
+	}
+	return selected
+}
+
+// String returns strings unquoted, null as empty string and other values as
+// compact JSON
+func String(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		sb := &strings.Builder{}
+		e := json.NewEncoder(sb)
+		e.SetEscapeHTML(false)
+		_ = e.Encode(v)
+		return strings.TrimSuffix(sb.String(), "\n")
+	}
+}
+
+// Fields returns object fields as strings, nil if not an object
+func Fields(v any) map[string]string {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	fields := map[string]string{}
+	for k, fv := range m {
+		fields[k] = String(fv)
+	}
+	return fields
+}
@@ -0,0 +1,455 @@
+// Package xpath implements a XML tree and a subset of XPath 1.0 to select nodes
+//
+// Supported:
+//
+//	/a/b, //b, a/b (same as /a/b), ., .., *, @attr, @*, text()
+//	[n], [last()], [@attr], [name], [@attr='v'], [name!='v'], [.='v'],
+//	[contains(@attr,'v')], [starts-with(name,'v')]
+//
+// Namespace prefixes are ignored and names are matched by local name.
+package xpath
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NodeType is type of node
+type NodeType int
+
+// Node types
+const (
+	DocumentNode NodeType = iota
+	ElementNode
+	AttributeNode
+	TextNode
+)
+
+// Node in a XML tree
+type Node struct {
+	Type     NodeType
+	Name     string // local name for elements and attributes
+	Data     string // value for attributes and text
+	Attrs    []*Node
+	Children []*Node // elements and text
+	Parent   *Node
+}
+
+// Text returns all text for document or elements otherwise value
+func (n *Node) Text() string {
+	switch n.Type {
+	case DocumentNode, ElementNode:
+		sb := &strings.Builder{}
+		var walk func(n *Node)
+		walk = func(n *Node) {
+			for _, c := range n.Children {
+				if c.Type == TextNode {
+					sb.WriteString(c.Data)
+				} else {
+					walk(c)
+				}
+			}
+		}
+		walk(n)
+		return sb.String()
+	default:
+		return n.Data
+	}
+}
+
+// Attr returns attribute value by local name
+func (n *Node) Attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name == name {
+			return a.Data, true
+		}
+	}
+	return "", false
+}
+
+// Decode XML into a tree, non-strict and with HTML entities
+func Decode(r io.Reader) (*Node, error) {
+	doc := &Node{Type: DocumentNode}
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	// assume charset is compatible with UTF-8 ex ISO-8859-1 with ASCII content
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	cur := doc
+	for {
+		t, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			n := &Node{Type: ElementNode, Name: t.Name.Local, Parent: cur}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				n.Attrs = append(n.Attrs, &Node{Type: AttributeNode, Name: a.Name.Local, Data: a.Value, Parent: n})
+			}
+			cur.Children = append(cur.Children, n)
+			cur = n
+		case xml.EndElement:
+			if cur.Parent != nil {
+				cur = cur.Parent
+			}
+		case xml.CharData:
+			cur.Children = append(cur.Children, &Node{Type: TextNode, Data: string(t), Parent: cur})
+		}
+	}
+	if len(doc.Children) == 0 {
+		return nil, fmt.Errorf("no root element")
+	}
+
+	return doc, nil
+}
+
+type axis int
+
+const (
+	axisChild axis = iota
+	axisDescendant
+)
+
+type predicate struct {
+	position int  // 1-based, 0 if not a position
+	last     bool // last()
+	lhs      string
+	op       string // "" exists, "=", "!=", "contains", "starts-with"
+	rhs      string
+}
+
+type step struct {
+	axis       axis
+	test       string // name, *, @name, @*, text(), ., ..
+	predicates []predicate
+}
+
+// Expr is a parsed expression
+type Expr struct {
+	steps []step
+}
+
+// split on s at top level, outside brackets and quotes
+func splitSteps(s string) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func localName(s string) string {
+	if _, l, ok := strings.Cut(s, ":"); ok {
+		return l
+	}
+	return s
+}
+
+func unquote(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+func parsePredicate(s string) (predicate, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 {
+			return predicate{}, fmt.Errorf("position should be >= 1")
+		}
+		return predicate{position: n}, nil
+	}
+	if s == "last()" {
+		return predicate{last: true}, nil
+	}
+	for _, fn := range []string{"contains", "starts-with"} {
+		if args, ok := strings.CutPrefix(s, fn+"("); ok && strings.HasSuffix(args, ")") {
+			lhs, rhs, ok := strings.Cut(args[0:len(args)-1], ",")
+			if !ok {
+				return predicate{}, fmt.Errorf("%s needs two arguments", fn)
+			}
+			rhsStr, ok := unquote(strings.TrimSpace(rhs))
+			if !ok {
+				return predicate{}, fmt.Errorf("%s second argument should be a string", fn)
+			}
+			return predicate{lhs: strings.TrimSpace(lhs), op: fn, rhs: rhsStr}, nil
+		}
+	}
+	for _, op := range []string{"!=", "="} {
+		if lhs, rhs, ok := strings.Cut(s, op); ok {
+			rhsStr, ok := unquote(strings.TrimSpace(rhs))
+			if !ok {
+				return predicate{}, fmt.Errorf("right side of %s should be a string", op)
+			}
+			return predicate{lhs: strings.TrimSpace(lhs), op: op, rhs: rhsStr}, nil
+		}
+	}
+	return predicate{lhs: s}, nil
+}
+
+func validTest(s string) bool {
+	s = strings.TrimPrefix(s, "@")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '*' || c == '-' || c == '_' || c == '.' || c == ':' ||
+			(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c > 0x7f) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseStep(s string) (step, error) {
+	var st step
+	n := strings.Index(s, "[")
+	if n == -1 {
+		n = len(s)
+	}
+	st.test = strings.TrimSpace(s[0:n])
+	if st.test != "text()" && !validTest(st.test) {
+		return step{}, fmt.Errorf("invalid step %q", s)
+	}
+	rest := s[n:]
+	for rest != "" {
+		if rest[0] != '[' {
+			return step{}, fmt.Errorf("invalid step %q", s)
+		}
+		// find matching ] outside quotes
+		end := -1
+		var quote byte
+		for i := 1; i < len(rest); i++ {
+			c := rest[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if c == '\'' || c == '"' {
+				quote = c
+			} else if c == ']' {
+				end = i
+				break
+			}
+		}
+		if end == -1 {
+			return step{}, fmt.Errorf("invalid step %q: missing ]", s)
+		}
+		p, err := parsePredicate(rest[1:end])
+		if err != nil {
+			return step{}, fmt.Errorf("invalid step %q: %w", s, err)
+		}
+		st.predicates = append(st.predicates, p)
+		rest = rest[end+1:]
+	}
+	return st, nil
+}
+
+// Parse expression
+func Parse(s string) (Expr, error) {
+	if s == "" {
+		return Expr{}, fmt.Errorf("empty expression")
+	}
+	p := strings.TrimPrefix(s, "/")
+	var steps []step
+	nextAxis := axisChild
+	for _, part := range splitSteps(p) {
+		// empty part is from // separator
+		if part == "" {
+			if nextAxis == axisDescendant {
+				return Expr{}, fmt.Errorf("invalid expression %q", s)
+			}
+			nextAxis = axisDescendant
+			continue
+		}
+		st, err := parseStep(part)
+		if err != nil {
+			return Expr{}, err
+		}
+		st.axis = nextAxis
+		nextAxis = axisChild
+		steps = append(steps, st)
+	}
+	if nextAxis == axisDescendant || len(steps) == 0 {
+		return Expr{}, fmt.Errorf("invalid expression %q", s)
+	}
+	for _, st := range steps[0 : len(steps)-1] {
+		if strings.HasPrefix(st.test, "@") || st.test == "text()" {
+			return Expr{}, fmt.Errorf("invalid expression %q: %s should be last", s, st.test)
+		}
+	}
+
+	return Expr{steps: steps}, nil
+}
+
+func matchName(test string, name string) bool {
+	return test == "*" || localName(test) == name
+}
+
+// candidates for a step from a context node
+func (st step) candidates(n *Node) []*Node {
+	var ns []*Node
+	switch {
+	case st.test == ".":
+		ns = append(ns, n)
+	case st.test == "..":
+		if n.Parent != nil {
+			ns = append(ns, n.Parent)
+		}
+	case st.test == "text()":
+		for _, c := range n.Children {
+			if c.Type == TextNode {
+				ns = append(ns, c)
+			}
+		}
+	case strings.HasPrefix(st.test, "@"):
+		for _, a := range n.Attrs {
+			if matchName(st.test[1:], a.Name) {
+				ns = append(ns, a)
+			}
+		}
+	default:
+		for _, c := range n.Children {
+			if c.Type == ElementNode && matchName(st.test, c.Name) {
+				ns = append(ns, c)
+			}
+		}
+	}
+	return ns
+}
+
+// values of lhs in a predicate, no values means it does not exist
+func (p predicate) values(n *Node) []string {
+	switch {
+	case p.lhs == "." || p.lhs == "text()":
+		return []string{n.Text()}
+	case strings.HasPrefix(p.lhs, "@"):
+		var vs []string
+		for _, a := range n.Attrs {
+			if matchName(p.lhs[1:], a.Name) {
+				vs = append(vs, a.Data)
+			}
+		}
+		return vs
+	default:
+		var vs []string
+		for _, c := range n.Children {
+			if c.Type == ElementNode && matchName(p.lhs, c.Name) {
+				vs = append(vs, c.Text())
+			}
+		}
+		return vs
+	}
+}
+
+func (p predicate) match(n *Node, position int, size int) bool {
+	switch {
+	case p.position != 0:
+		return position == p.position
+	case p.last:
+		return position == size
+	}
+	vs := p.values(n)
+	if p.op == "" {
+		return len(vs) > 0
+	}
+	for _, v := range vs {
+		switch p.op {
+		case "=":
+			if v == p.rhs {
+				return true
+			}
+		case "!=":
+			if v != p.rhs {
+				return true
+			}
+		case "contains":
+			if strings.Contains(v, p.rhs) {
+				return true
+			}
+		case "starts-with":
+			if strings.HasPrefix(v, p.rhs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func descendantOrSelf(n *Node) []*Node {
+	ns := []*Node{n}
+	for _, c := range n.Children {
+		if c.Type == ElementNode {
+			ns = append(ns, descendantOrSelf(c)...)
+		}
+	}
+	return ns
+}
+
+// Select nodes in document order
+func (e Expr) Select(doc *Node) []*Node {
+	ctx := []*Node{doc}
+	for _, st := range e.steps {
+		var next []*Node
+		seen := map[*Node]bool{}
+		for _, c := range ctx {
+			bases := []*Node{c}
+			if st.axis == axisDescendant {
+				bases = descendantOrSelf(c)
+			}
+			for _, b := range bases {
+				ns := st.candidates(b)
+				for _, p := range st.predicates {
+					var filtered []*Node
+					for i, n := range ns {
+						if p.match(n, i+1, len(ns)) {
+							filtered = append(filtered, n)
+						}
+					}
+					ns = filtered
+				}
+				for _, n := range ns {
+					if seen[n] {
+						continue
+					}
+					seen[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		ctx = next
+	}
+	return ctx
+}
@@ -0,0 +1,84 @@
+package xpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/xpath"
+)
+
+const doc = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata xmlns="http://maven.apache.org/METADATA/1.1.0">
+  <versioning>
+    <release>1.2</release>
+    <versions>
+      <version>1.0</version>
+      <version type="rc">1.1-rc1</version>
+      <version>1.2</version>
+    </versions>
+  </versioning>
+  <a:link xmlns:a="urn:a" a:href="https://example.com/&amp;x">link &nbsp;text</a:link>
+</metadata>
+`
+
+func TestSelect(t *testing.T) {
+	testCases := []struct {
+		expr        string
+		expected    []string
+		expectedErr string
+	}{
+		{expr: "/metadata/versioning/release", expected: []string{"1.2"}},
+		{expr: "metadata/versioning/release", expected: []string{"1.2"}},
+		{expr: "//version", expected: []string{"1.0", "1.1-rc1", "1.2"}},
+		{expr: "//versions/*[2]", expected: []string{"1.1-rc1"}},
+		{expr: "//version[last()]", expected: []string{"1.2"}},
+		{expr: "//version[@type]", expected: []string{"1.1-rc1"}},
+		{expr: "//version[@type='rc']/@type", expected: []string{"rc"}},
+		{expr: "//version[not-there]", expected: nil},
+		{expr: "//version[.!='1.0'][1]", expected: []string{"1.1-rc1"}},
+		{expr: "//version[contains(., 'rc')]/text()", expected: []string{"1.1-rc1"}},
+		{expr: "//version[starts-with(text(), '1.2')]/..//version[1]", expected: []string{"1.0"}},
+		{expr: "//versioning[release='1.2']/release", expected: []string{"1.2"}},
+		{expr: "//a:link/@a:href", expected: []string{"https://example.com/&x"}},
+		{expr: "//link", expected: []string{"link \u00a0text"}},
+		{expr: "", expectedErr: "empty expression"},
+		{expr: "//", expectedErr: `invalid expression "//"`},
+		{expr: "a///b", expectedErr: `invalid expression "a///b"`},
+		{expr: "a/@b/c", expectedErr: `invalid expression "a/@b/c": @b should be last`},
+		{expr: "a[1", expectedErr: `invalid step "a[1": missing ]`},
+		{expr: "a[0]", expectedErr: `invalid step "a[0]": position should be >= 1`},
+		{expr: "a[b=c]", expectedErr: `invalid step "a[b=c]": right side of = should be a string`},
+		{expr: "a b", expectedErr: `invalid step "a b"`},
+	}
+	for _, tC := range testCases {
+		tC := tC
+		t.Run(tC.expr, func(t *testing.T) {
+			e, err := xpath.Parse(tC.expr)
+			if tC.expectedErr != "" {
+				if err == nil || err.Error() != tC.expectedErr {
+					t.Fatalf("expected error %q got %v", tC.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := xpath.Decode(strings.NewReader(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			for _, n := range e.Select(d) {
+				actual = append(actual, n.Text())
+			}
+			deepequal.Error(t, "select", tC.expected, actual)
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	if _, err := xpath.Decode(strings.NewReader("")); err == nil || err.Error() != "no root element" {
+		t.Fatalf("expected no root element error got %v", err)
+	}
+}
@@ -0,0 +1,653 @@
+// Package yaml implements a decoder for a subset of YAML 1.2
+// Supports block mappings and sequences, plain, quoted and block scalars and
+// flow collections. Anchors, aliases, tags, complex keys and multiple
+// documents are not supported, anchors, aliases and tags are errors.
+// All scalars are decoded as strings and nulls as nil.
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var errUnterminated = errors.New("unexpected end of input")
+var errUnsupported = errors.New("anchors, aliases and tags are not supported")
+var errTabIndent = errors.New("tabs are not allowed as indentation")
+
+// isUnsupported is true for start of a anchor "&a", alias "*a" or tag "!t"
+func isUnsupported(s string) bool {
+	return s != "" && strings.IndexByte("&*!", s[0]) != -1
+}
+
+type line struct {
+	nr     int
+	indent int
+	text   string // without indent and comment, empty if blank
+	raw    string
+}
+
+type decoder struct {
+	lines []line
+	pos   int
+}
+
+// Unmarshal decodes first document in data into map[string]any, []any,
+// string or nil values
+func Unmarshal(data []byte) (any, error) {
+	d := &decoder{}
+	s := strings.TrimPrefix(string(data), "\ufeff")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	seenContent := false
+	for i, raw := range strings.Split(s, "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(trimmed)
+		text := strings.TrimRight(stripComment(trimmed), " \t")
+
+		if indent == 0 {
+			isMarker := text == "---" || strings.HasPrefix(text, "--- ") || text == "..."
+			if isMarker && seenContent {
+				// only first document is decoded
+				break
+			}
+			if isMarker || strings.HasPrefix(text, "%") {
+				continue
+			}
+		}
+		if text != "" {
+			seenContent = true
+		}
+
+		d.lines = append(d.lines, line{
+			nr:     i + 1,
+			indent: indent,
+			text:   text,
+			raw:    raw,
+		})
+	}
+
+	v, err := d.node(0)
+	if err != nil {
+		return nil, err
+	}
+	if l, ok := d.peek(); ok {
+		return nil, fmt.Errorf("line %d: unexpected content", l.nr)
+	}
+
+	return v, nil
+}
+
+// stripComment removes "# comment" that is not inside quotes
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,", s[i-1]) != -1):
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[0:i]
+		}
+	}
+	return s
+}
+
+// peek skips blank lines and returns next line without consuming it
+func (d *decoder) peek() (*line, bool) {
+	for d.pos < len(d.lines) && d.lines[d.pos].text == "" {
+		d.pos++
+	}
+	if d.pos >= len(d.lines) {
+		return nil, false
+	}
+	return &d.lines[d.pos], true
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits "key: value" into key and value
+func splitKey(text string) (key string, rest string, ok bool) {
+	if text == "" {
+		return "", "", false
+	}
+
+	var after string
+	switch {
+	case text[0] == '"' || text[0] == '\'':
+		k, r, err := parseQuoted(text)
+		if err != nil {
+			return "", "", false
+		}
+		key = k
+		after = strings.TrimLeft(r, " ")
+		if !strings.HasPrefix(after, ":") {
+			return "", "", false
+		}
+	case strings.IndexByte("[{|>", text[0]) != -1 || isSeqItem(text):
+		return "", "", false
+	default:
+		n := strings.Index(text, ": ")
+		if n == -1 {
+			if !strings.HasSuffix(text, ":") {
+				return "", "", false
+			}
+			n = len(text) - 1
+		}
+		key = strings.TrimRight(text[0:n], " ")
+		after = text[n:]
+	}
+
+	if len(after) > 1 && after[1] != ' ' {
+		return "", "", false
+	}
+
+	return key, strings.TrimSpace(after[1:]), true
+}
+
+func (d *decoder) node(minIndent int) (any, error) {
+	l, ok := d.peek()
+	if !ok || l.indent < minIndent {
+		return nil, nil
+	}
+	if strings.HasPrefix(l.text, "\t") {
+		return nil, fmt.Errorf("line %d: %w", l.nr, errTabIndent)
+	}
+
+	if isSeqItem(l.text) {
+		return d.seq(l.indent)
+	}
+	if _, _, ok := splitKey(l.text); ok {
+		return d.mapping(l.indent)
+	}
+
+	d.pos++
+	return d.value(l.text, minIndent-1, l.nr)
+}
+
+func (d *decoder) mapping(indent int) (any, error) {
+	m := map[string]any{}
+	for {
+		l, ok := d.peek()
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(l.text, "\t") {
+			return nil, fmt.Errorf("line %d: %w", l.nr, errTabIndent)
+		}
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indent", l.nr)
+		}
+		key, rest, ok := splitKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected mapping key", l.nr)
+		}
+		if isUnsupported(l.text) {
+			return nil, fmt.Errorf("line %d: %w", l.nr, errUnsupported)
+		}
+		d.pos++
+
+		var v any
+		var err error
+		if rest == "" {
+			n, ok := d.peek()
+			switch {
+			case ok && n.indent > indent:
+				v, err = d.node(indent + 1)
+			case ok && n.indent == indent && isSeqItem(n.text):
+				// sequence as value can have same indent as key
+				v, err = d.seq(indent)
+			}
+		} else {
+			v, err = d.value(rest, indent, l.nr)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = v
+	}
+
+	return m, nil
+}
+
+func (d *decoder) seq(indent int) (any, error) {
+	s := []any{}
+	for {
+		l, ok := d.peek()
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(l.text, "\t") {
+			return nil, fmt.Errorf("line %d: %w", l.nr, errTabIndent)
+		}
+		if l.indent != indent || !isSeqItem(l.text) {
+			break
+		}
+
+		var v any
+		var err error
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if rest == "" {
+			d.pos++
+			v, err = d.node(indent + 1)
+		} else {
+			// "- a: b" is parsed as "a: b" with indent of "a"
+			l.indent = indent + len(l.text) - len(rest)
+			l.text = rest
+			v, err = d.node(l.indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, v)
+	}
+
+	return s, nil
+}
+
+// value parses a scalar or flow collection starting with s, following lines
+// with more indent than parentIndent can be part of the value
+func (d *decoder) value(s string, parentIndent int, nr int) (any, error) {
+	if isUnsupported(s) {
+		return nil, fmt.Errorf("line %d: %w", nr, errUnsupported)
+	}
+
+	switch s[0] {
+	case '|', '>':
+		return d.blockScalar(s, parentIndent, nr)
+	case '"', '\'':
+		full := s
+		for {
+			v, rest, err := parseQuoted(full)
+			if errors.Is(err, errUnterminated) && d.pos < len(d.lines) {
+				full += "\n" + strings.TrimSpace(d.lines[d.pos].raw)
+				d.pos++
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: %w", nr, err)
+			}
+			rest = strings.TrimSpace(rest)
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after quoted scalar", nr, rest)
+			}
+			return v, nil
+		}
+	case '[', '{':
+		full := s
+		for {
+			fp := &flowParser{s: full}
+			v, err := fp.value()
+			if errors.Is(err, errUnterminated) && d.pos < len(d.lines) {
+				full += "\n" + d.lines[d.pos].text
+				d.pos++
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: %w", nr, err)
+			}
+			fp.ws()
+			if fp.i != len(fp.s) {
+				return nil, fmt.Errorf("line %d: unexpected %q after flow collection", nr, fp.s[fp.i:])
+			}
+			return v, nil
+		}
+	}
+
+	// plain scalar, can continue on lines with more indent
+	sb := &strings.Builder{}
+	sb.WriteString(s)
+	blanks := 0
+	for d.pos < len(d.lines) {
+		l := d.lines[d.pos]
+		if l.text == "" {
+			blanks++
+			d.pos++
+			continue
+		}
+		if l.indent <= parentIndent {
+			break
+		}
+		if blanks > 0 {
+			sb.WriteString(strings.Repeat("\n", blanks))
+		} else {
+			sb.WriteByte(' ')
+		}
+		blanks = 0
+		sb.WriteString(l.text)
+		d.pos++
+	}
+
+	return plain(sb.String()), nil
+}
+
+func plain(s string) any {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	}
+	return s
+}
+
+// blockScalar parses literal "|" or folded ">" block scalar with optional
+// chomping "-" or "+" and indentation indicator
+func (d *decoder) blockScalar(header string, parentIndent int, nr int) (any, error) {
+	literal := header[0] == '|'
+	chomp := byte(0)
+	explicitIndent := 0
+	for _, c := range []byte(header[1:]) {
+		switch {
+		case c == '-' || c == '+':
+			chomp = c
+		case c >= '1' && c <= '9':
+			explicitIndent = int(c - '0')
+		default:
+			return nil, fmt.Errorf("line %d: invalid block scalar header %q", nr, header)
+		}
+	}
+
+	contentIndent := -1
+	if explicitIndent > 0 {
+		contentIndent = max(parentIndent, 0) + explicitIndent
+	}
+	var lines []string
+	for d.pos < len(d.lines) {
+		raw := d.lines[d.pos].raw
+		trimmed := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(trimmed)
+		if trimmed == "" {
+			lines = append(lines, "")
+			d.pos++
+			continue
+		}
+		if contentIndent == -1 {
+			if indent <= parentIndent {
+				break
+			}
+			contentIndent = indent
+		}
+		if indent < contentIndent {
+			break
+		}
+		lines = append(lines, raw[contentIndent:])
+		d.pos++
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[0 : len(lines)-1]
+		trailing++
+	}
+	if len(lines) == 0 {
+		if chomp == '+' {
+			return strings.Repeat("\n", trailing), nil
+		}
+		return "", nil
+	}
+
+	sb := &strings.Builder{}
+	if literal {
+		sb.WriteString(strings.Join(lines, "\n"))
+	} else {
+		// line breaks between lines are folded into a space, blank lines into
+		// newlines and more indented lines keep their line breaks
+		blanks := 0
+		prevMore := false
+		for i, l := range lines {
+			if l == "" {
+				blanks++
+				continue
+			}
+			more := strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")
+			if i > 0 {
+				if blanks == 0 && !more && !prevMore {
+					sb.WriteByte(' ')
+				} else {
+					n := blanks
+					if more || prevMore {
+						n++
+					}
+					sb.WriteString(strings.Repeat("\n", n))
+				}
+			}
+			sb.WriteString(l)
+			blanks = 0
+			prevMore = more
+		}
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		sb.WriteString(strings.Repeat("\n", trailing+1))
+	default:
+		sb.WriteByte('\n')
+	}
+
+	return sb.String(), nil
+}
+
+// parseQuoted parses a single or double quoted scalar at start of s and
+// returns the rest of s after the closing quote
+func parseQuoted(s string) (string, string, error) {
+	quote := s[0]
+	sb := &strings.Builder{}
+
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			sb.WriteByte('\'')
+			i++
+		case c == quote:
+			return sb.String(), s[i+1:], nil
+		case c == '\n':
+			// line breaks are folded into a space, empty lines into newlines
+			folded := strings.TrimRight(sb.String(), " \t")
+			sb.Reset()
+			sb.WriteString(folded)
+			newlines := 0
+			for ; i < len(s) && (s[i] == '\n' || s[i] == ' ' || s[i] == '\t'); i++ {
+				if s[i] == '\n' {
+					newlines++
+				}
+			}
+			i--
+			if newlines == 1 {
+				sb.WriteByte(' ')
+			} else {
+				sb.WriteString(strings.Repeat("\n", newlines-1))
+			}
+		case c == '\\' && quote == '"':
+			if i+1 >= len(s) {
+				return "", "", errUnterminated
+			}
+			i++
+			n, err := writeEscape(sb, s[i:])
+			if err != nil {
+				return "", "", err
+			}
+			i += n - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", "", errUnterminated
+}
+
+var simpleEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028",
+	'P': "\u2029",
+}
+
+// writeEscape writes escape sequence at start of s (after "\") and returns
+// its length
+func writeEscape(sb *strings.Builder, s string) (int, error) {
+	if r, ok := simpleEscapes[s[0]]; ok {
+		sb.WriteString(r)
+		return 1, nil
+	}
+
+	var hexLen int
+	switch s[0] {
+	case '\n':
+		// escaped line break, skip leading white space on next line
+		n := 1
+		for n < len(s) && (s[n] == ' ' || s[n] == '\t') {
+			n++
+		}
+		return n, nil
+	case 'x':
+		hexLen = 2
+	case 'u':
+		hexLen = 4
+	case 'U':
+		hexLen = 8
+	default:
+		return 0, fmt.Errorf("invalid escape %q", "\\"+string(s[0]))
+	}
+	if len(s) < 1+hexLen {
+		return 0, errUnterminated
+	}
+	r, err := strconv.ParseUint(s[1:1+hexLen], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return 0, fmt.Errorf("invalid escape %q", "\\"+s[0:1+hexLen])
+	}
+	sb.WriteRune(rune(r))
+
+	return 1 + hexLen, nil
+}
+
+type flowParser struct {
+	s string
+	i int
+}
+
+func (p *flowParser) ws() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\n", p.s[p.i]) != -1 {
+		p.i++
+	}
+}
+
+func (p *flowParser) value() (any, error) {
+	p.ws()
+	if p.i >= len(p.s) {
+		return nil, errUnterminated
+	}
+
+	switch p.s[p.i] {
+	case '[':
+		p.i++
+		s := []any{}
+		for {
+			p.ws()
+			if p.i >= len(p.s) {
+				return nil, errUnterminated
+			}
+			if p.s[p.i] == ']' {
+				p.i++
+				return s, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+			if err := p.sep(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		p.i++
+		m := map[string]any{}
+		for {
+			p.ws()
+			if p.i >= len(p.s) {
+				return nil, errUnterminated
+			}
+			if p.s[p.i] == '}' {
+				p.i++
+				return m, nil
+			}
+			k, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			ks, _ := k.(string)
+			p.ws()
+			var v any
+			if p.i < len(p.s) && p.s[p.i] == ':' {
+				p.i++
+				v, err = p.value()
+				if err != nil {
+					return nil, err
+				}
+			}
+			m[ks] = v
+			if err := p.sep('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '"', '\'':
+		v, rest, err := parseQuoted(p.s[p.i:])
+		if err != nil {
+			return nil, err
+		}
+		p.i = len(p.s) - len(rest)
+		return v, nil
+	}
+
+	if isUnsupported(p.s[p.i:]) {
+		return nil, errUnsupported
+	}
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if c == ',' || c == ']' || c == '}' || c == '[' || c == '{' {
+			break
+		}
+		if c == ':' && (p.i+1 == len(p.s) || strings.IndexByte(" \t\n,]}", p.s[p.i+1]) != -1) {
+			break
+		}
+		p.i++
+	}
+	s := strings.Join(strings.Fields(p.s[start:p.i]), " ")
+	if s == "" {
+		// empty value, "{a: }"
+		return nil, nil
+	}
+
+	return plain(s), nil
+}
+
+// sep expects "," or end of collection
+func (p *flowParser) sep(end byte) error {
+	p.ws()
+	if p.i >= len(p.s) {
+		return errUnterminated
+	}
+	switch p.s[p.i] {
+	case ',':
+		p.i++
+		return nil
+	case end:
+		return nil
+	}
+	return fmt.Errorf("unexpected %q in flow collection", p.s[p.i:p.i+1])
+}
@@ -0,0 +1,171 @@
+package yaml_test
+
+import (
+	"testing"
+
+	"github.com/wader/bump/internal/deepequal"
+	"github.com/wader/bump/internal/yaml"
+)
+
+func TestUnmarshal(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		s           string
+		expected    any
+		expectedErr string
+	}{
+		{desc: "empty", s: ``, expected: nil},
+		{desc: "scalar", s: `a`, expected: "a"},
+		{desc: "null", s: `~`, expected: nil},
+		{desc: "mapping", s: "a: 1\nb: true\nc:\n", expected: map[string]any{"a": "1", "b": "true", "c": nil}},
+		{
+			desc: "nested mapping",
+			s: `
+# comment
+a:
+  b: c # comment
+  d:
+    e: f
+g: h
+`,
+			expected: map[string]any{
+				"a": map[string]any{"b": "c", "d": map[string]any{"e": "f"}},
+				"g": "h",
+			},
+		},
+		{
+			desc: "sequence",
+			s: `
+- a
+- b: c
+  d: e
+-
+  - f
+  - g
+- - h
+`,
+			expected: []any{
+				"a",
+				map[string]any{"b": "c", "d": "e"},
+				[]any{"f", "g"},
+				[]any{"h"},
+			},
+		},
+		{
+			desc: "sequence same indent as key",
+			s: `
+a:
+- b
+- c
+d: e
+`,
+			expected: map[string]any{"a": []any{"b", "c"}, "d": "e"},
+		},
+		{
+			desc: "quoted",
+			s: `
+a: "b # c\tå"
+'d': 'e''f'
+"g h": "i
+  j"
+k: "http://a"
+`,
+			expected: map[string]any{"a": "b # c\tå", "d": "e'f", "g h": "i j", "k": "http://a"},
+		},
+		{
+			desc: "plain multi-line and colons",
+			s: `
+a: b
+  c
+
+  d
+url: http://a:80/b
+e: f:g
+`,
+			expected: map[string]any{"a": "b c\nd", "url": "http://a:80/b", "e": "f:g"},
+		},
+		{
+			desc: "block scalars",
+			s: `
+a: |
+  b
+   c
+  # d
+e: >-
+  f
+  g
+
+  h
+i: |+
+  j
+
+k: |-
+  l
+`,
+			expected: map[string]any{
+				"a": "b\n c\n# d\n",
+				"e": "f g\nh",
+				"i": "j\n\n",
+				"k": "l",
+			},
+		},
+		{
+			desc: "flow",
+			s: `
+a: []
+b: {}
+c: [d, "e, f", [g]]
+h: {i: j, k: [l], m: }
+n: [
+  o,
+  p
+]
+`,
+			expected: map[string]any{
+				"a": []any{},
+				"b": map[string]any{},
+				"c": []any{"d", "e, f", []any{"g"}},
+				"h": map[string]any{"i": "j", "k": []any{"l"}, "m": nil},
+				"n": []any{"o", "p"},
+			},
+		},
+		{
+			desc:     "documents",
+			s:        "%YAML 1.2\n---\na: b\n---\nc: d\n",
+			expected: map[string]any{"a": "b"},
+		},
+		{desc: "err unexpected indent", s: "a:\n  b: c\n d: e\n", expectedErr: "line 3: unexpected indent"},
+		{desc: "err unterminated quoted", s: "a: \"b\n", expectedErr: "line 1: unexpected end of input"},
+		{desc: "err unterminated flow", s: "a: [b\n", expectedErr: "line 1: unexpected end of input"},
+		{desc: "err expected key", s: "a: b\nc\n", expectedErr: "line 2: expected mapping key"},
+		{desc: "tab in block scalar", s: "a: |\n  \tb\n", expected: map[string]any{"a": "\tb\n"}},
+		{desc: "err anchor", s: "a: &x b\n", expectedErr: "line 1: anchors, aliases and tags are not supported"},
+		{desc: "err anchor mapping", s: "a: &x\n  b: c\n", expectedErr: "line 1: anchors, aliases and tags are not supported"},
+		{desc: "err alias", s: "a: b\nc: *x\n", expectedErr: "line 2: anchors, aliases and tags are not supported"},
+		{desc: "err alias merge key", s: "a:\n  <<: *x\n", expectedErr: "line 2: anchors, aliases and tags are not supported"},
+		{desc: "err anchor key", s: "&x a: b\n", expectedErr: "line 1: anchors, aliases and tags are not supported"},
+		{desc: "err tag", s: "a: !!str 1\n", expectedErr: "line 1: anchors, aliases and tags are not supported"},
+		{desc: "err tag sequence item", s: "- !t a\n", expectedErr: "line 1: anchors, aliases and tags are not supported"},
+		{desc: "err alias flow", s: "a: [b, *x]\n", expectedErr: "line 1: anchors, aliases and tags are not supported"},
+		{desc: "err tab indent", s: "a:\n\tb: c\n", expectedErr: "line 2: tabs are not allowed as indentation"},
+		{desc: "err tab indent after spaces", s: "a:\n  b: c\n \td: e\n", expectedErr: "line 3: tabs are not allowed as indentation"},
+		{desc: "err tab indent sequence", s: "- a\n\t- b\n", expectedErr: "line 2: tabs are not allowed as indentation"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, err := yaml.Unmarshal([]byte(tC.s))
+			if tC.expectedErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %q got success", tC.expectedErr)
+				} else if err.Error() != tC.expectedErr {
+					t.Fatalf("expected error %q got %q", tC.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			deepequal.Error(t, "value", tC.expected, actual)
+		})
+	}
+}